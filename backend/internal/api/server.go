@@ -56,13 +56,22 @@ func NewServer(db *gorm.DB, redis *redis.Client, cfg *config.Config) *Server {
 func (s *Server) setupRoutes() {
 	userRepo := repositories.NewUserRepository(s.db)
 	urlRepo := repositories.NewURLRepository(s.db)
+	ruleRepo := repositories.NewExtractionRuleRepository(s.db)
+	profileRepo := repositories.NewCrawlProfileRepository(s.db)
 
 	authService := services.NewAuthService(userRepo, s.config.JWTSecret)
 	queueService := services.NewQueueService(s.redis)
-	urlService := services.NewURLService(urlRepo, queueService)
+	urlService := services.NewURLService(urlRepo, profileRepo, queueService)
+	sitemapService := services.NewSitemapService(urlService, s.config)
+	ruleService := services.NewExtractionRuleService(ruleRepo, urlRepo)
+	profileService := services.NewCrawlProfileService(profileRepo, s.config)
 
 	authHandler := handlers.NewAuthHandler(authService)
 	urlHandler := handlers.NewURLHandler(urlService)
+	sitemapHandler := handlers.NewSitemapHandler(sitemapService)
+	ruleHandler := handlers.NewExtractionRuleHandler(ruleService)
+	analyzerHandler := handlers.NewAnalyzerHandler(s.config.DisabledAnalyzers)
+	profileHandler := handlers.NewCrawlProfileHandler(profileService)
 
 	api := s.router.Group("/api/v1")
 	{
@@ -80,9 +89,32 @@ func (s *Server) setupRoutes() {
 			{
 				urls.POST("", urlHandler.AddURL)
 				urls.GET("", urlHandler.GetAllURLs)
+				urls.GET("/duplicates", urlHandler.GetDuplicates)
 				urls.GET("/:id", urlHandler.GetURL)
+				urls.PATCH("/:id", urlHandler.UpdateURLSettings)
+				urls.GET("/:id/links", urlHandler.GetURLLinks)
 				urls.POST("/bulk", urlHandler.BulkAction)
+				urls.POST("/import-sitemap", sitemapHandler.ImportSitemap)
 			}
+
+			rules := protected.Group("/extraction-rules")
+			{
+				rules.POST("", ruleHandler.CreateRule)
+				rules.GET("", ruleHandler.GetRules)
+				rules.PUT("/:id", ruleHandler.UpdateRule)
+				rules.DELETE("/:id", ruleHandler.DeleteRule)
+			}
+
+			profiles := protected.Group("/crawl-profiles")
+			{
+				profiles.POST("", profileHandler.CreateProfile)
+				profiles.GET("", profileHandler.GetProfiles)
+				profiles.GET("/:id", profileHandler.GetProfile)
+				profiles.PUT("/:id", profileHandler.UpdateProfile)
+				profiles.DELETE("/:id", profileHandler.DeleteProfile)
+			}
+
+			protected.GET("/analyzers", analyzerHandler.GetAnalyzers)
 		}
 	}
 
@@ -92,9 +124,10 @@ func (s *Server) setupRoutes() {
 func (s *Server) startBackgroundWorkers() {
 	urlRepo := repositories.NewURLRepository(s.db)
 	resultRepo := repositories.NewCrawlResultRepository(s.db)
+	ruleRepo := repositories.NewExtractionRuleRepository(s.db)
 
 	queueService := services.NewQueueService(s.redis)
-	crawlerService := services.NewCrawlerService(urlRepo, resultRepo, queueService, s.config)
+	crawlerService := services.NewCrawlerService(urlRepo, resultRepo, ruleRepo, queueService, s.config)
 
 	s.workerWg.Add(1)
 	go func() {
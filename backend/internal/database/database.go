@@ -40,10 +40,57 @@ func Connect(databaseURL string) (*gorm.DB, error) {
 }
 
 func Migrate(db *gorm.DB) error {
+	if err := dropLegacyIndexes(db); err != nil {
+		return err
+	}
+
 	return db.AutoMigrate(
 		&models.User{},
+		&models.CrawlProfile{},
 		&models.URL{},
 		&models.CrawlResult{},
 		&models.BrokenURL{},
+		&models.BlockedURL{},
+		&models.Link{},
+		&models.RedirectHop{},
+		&models.Heading{},
+		&models.AuthForm{},
+		&models.BrokenImage{},
+		&models.Resource{},
+		&models.MixedContent{},
+		&models.AccessibilityViolation{},
+		&models.ExtractedValue{},
+		&models.Finding{},
+		&models.ExtractionRule{},
+		&models.SEOMetadata{},
+		&models.SecurityAudit{},
+		&models.TLSInfo{},
+		&models.StructuredDataItem{},
 	)
-}
\ No newline at end of file
+}
+
+// dropLegacyIndexes removes indexes whose definition changed in a way that
+// AutoMigrate can't reconcile on its own.
+func dropLegacyIndexes(db *gorm.DB) error {
+	// crawl_results.url_id used to be unique, but site crawls store one row per page
+	if !db.Migrator().HasTable(&models.CrawlResult{}) {
+		return nil
+	}
+
+	indexes, err := db.Migrator().GetIndexes(&models.CrawlResult{})
+	if err != nil {
+		return err
+	}
+
+	for _, index := range indexes {
+		if index.Name() != "idx_crawl_results_url_id" {
+			continue
+		}
+		if unique, ok := index.Unique(); ok && unique {
+			log.Println("Dropping legacy unique index on crawl_results.url_id")
+			return db.Migrator().DropIndex(&models.CrawlResult{}, index.Name())
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"net/http"
+	"sykell-crawler/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyzerHandler struct {
+	disabled []string
+}
+
+// NewAnalyzerHandler creates a handler that reports the analyzers in disabled
+// as turned off by configuration.
+func NewAnalyzerHandler(disabled []string) *AnalyzerHandler {
+	return &AnalyzerHandler{disabled: disabled}
+}
+
+func (h *AnalyzerHandler) GetAnalyzers(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"analyzers": services.ListAnalyzers(h.disabled)})
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sykell-crawler/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestGetAnalyzers(t *testing.T) {
+	handler := NewAnalyzerHandler([]string{"meta-refresh"})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/analyzers", handler.GetAnalyzers)
+
+	req := httptest.NewRequest(http.MethodGet, "/analyzers", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response struct {
+		Analyzers []services.AnalyzerInfo `json:"analyzers"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	for _, analyzer := range response.Analyzers {
+		if analyzer.Name == "meta-refresh" {
+			if analyzer.Enabled {
+				t.Error("Expected meta-refresh to be reported as disabled")
+			}
+			return
+		}
+	}
+	t.Errorf("Expected meta-refresh to be listed, got %+v", response.Analyzers)
+}
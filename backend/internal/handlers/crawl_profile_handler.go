@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	stdErrors "errors"
+	"net/http"
+	"strconv"
+	"sykell-crawler/internal/errors"
+	"sykell-crawler/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CrawlProfileHandler struct {
+	profileService services.CrawlProfileService
+}
+
+func NewCrawlProfileHandler(profileService services.CrawlProfileService) *CrawlProfileHandler {
+	return &CrawlProfileHandler{profileService: profileService}
+}
+
+type CrawlProfileRequest struct {
+	Name               string            `json:"name" binding:"required,max=64"`
+	UserAgent          string            `json:"user_agent" binding:"max=512"`
+	Headers            map[string]string `json:"headers"`
+	Cookies            map[string]string `json:"cookies"`
+	BasicAuthUsername  string            `json:"basic_auth_username" binding:"max=255"`
+	BasicAuthPassword  string            `json:"basic_auth_password"`
+	TimeoutSeconds     int               `json:"timeout_seconds" binding:"min=0,max=300"`
+	SkipLinkChecks     bool              `json:"skip_link_checks"`
+	SkipImageChecks    bool              `json:"skip_image_checks"`
+	SkipResourceChecks bool              `json:"skip_resource_checks"`
+}
+
+func (r CrawlProfileRequest) input() services.CrawlProfileInput {
+	return services.CrawlProfileInput{
+		Name:               r.Name,
+		UserAgent:          r.UserAgent,
+		Headers:            r.Headers,
+		Cookies:            r.Cookies,
+		BasicAuthUsername:  r.BasicAuthUsername,
+		BasicAuthPassword:  r.BasicAuthPassword,
+		TimeoutSeconds:     r.TimeoutSeconds,
+		SkipLinkChecks:     r.SkipLinkChecks,
+		SkipImageChecks:    r.SkipImageChecks,
+		SkipResourceChecks: r.SkipResourceChecks,
+	}
+}
+
+func (h *CrawlProfileHandler) CreateProfile(c *gin.Context) {
+	var req CrawlProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.RespondWithError(c, errors.ValidationError(err.Error()))
+		return
+	}
+
+	profile, err := h.profileService.CreateProfile(req.input())
+	if err != nil {
+		respondWithProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, profile)
+}
+
+func (h *CrawlProfileHandler) GetProfiles(c *gin.Context) {
+	profiles, err := h.profileService.ListProfiles()
+	if err != nil {
+		errors.RespondWithStandardError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"profiles": profiles})
+}
+
+func (h *CrawlProfileHandler) GetProfile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errors.RespondWithError(c, errors.ValidationError("Invalid profile ID"))
+		return
+	}
+
+	profile, err := h.profileService.GetProfile(uint(id))
+	if err != nil {
+		respondWithProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func (h *CrawlProfileHandler) UpdateProfile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errors.RespondWithError(c, errors.ValidationError("Invalid profile ID"))
+		return
+	}
+
+	var req CrawlProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.RespondWithError(c, errors.ValidationError(err.Error()))
+		return
+	}
+
+	profile, err := h.profileService.UpdateProfile(uint(id), req.input())
+	if err != nil {
+		respondWithProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, profile)
+}
+
+func (h *CrawlProfileHandler) DeleteProfile(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errors.RespondWithError(c, errors.ValidationError("Invalid profile ID"))
+		return
+	}
+
+	if err := h.profileService.DeleteProfile(uint(id)); err != nil {
+		respondWithProfileError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Crawl profile deleted"})
+}
+
+func respondWithProfileError(c *gin.Context, err error) {
+	switch {
+	case stdErrors.Is(err, services.ErrProfileNotFound):
+		errors.RespondWithError(c, errors.NotFoundError(err.Error()))
+	case stdErrors.Is(err, services.ErrInvalidProfile):
+		errors.RespondWithError(c, errors.ValidationError(err.Error()))
+	default:
+		errors.RespondWithStandardError(c, err)
+	}
+}
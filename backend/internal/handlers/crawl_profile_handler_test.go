@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sykell-crawler/internal/models"
+	"sykell-crawler/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockCrawlProfileService struct {
+	profiles map[uint]*models.CrawlProfile
+	input    services.CrawlProfileInput
+}
+
+func (m *mockCrawlProfileService) CreateProfile(input services.CrawlProfileInput) (*models.CrawlProfile, error) {
+	m.input = input
+	if _, reserved := input.Headers["Cookie"]; reserved {
+		return nil, fmt.Errorf("%w: header %q can't be set on a profile", services.ErrInvalidProfile, "Cookie")
+	}
+	profile := &models.CrawlProfile{
+		ID:                1,
+		Name:              input.Name,
+		UserAgent:         input.UserAgent,
+		BasicAuthUsername: input.BasicAuthUsername,
+		BasicAuthPassword: "encrypted:" + input.BasicAuthPassword,
+	}
+	for name, value := range input.Cookies {
+		if profile.Cookies == nil {
+			profile.Cookies = make(map[string]string)
+		}
+		profile.Cookies[name] = "encrypted:" + value
+		profile.CookieNames = append(profile.CookieNames, name)
+	}
+	m.profiles[profile.ID] = profile
+	return profile, nil
+}
+
+func (m *mockCrawlProfileService) ListProfiles() ([]*models.CrawlProfile, error) {
+	var profiles []*models.CrawlProfile
+	for _, profile := range m.profiles {
+		profiles = append(profiles, profile)
+	}
+	return profiles, nil
+}
+
+func (m *mockCrawlProfileService) GetProfile(id uint) (*models.CrawlProfile, error) {
+	if profile, exists := m.profiles[id]; exists {
+		return profile, nil
+	}
+	return nil, services.ErrProfileNotFound
+}
+
+func (m *mockCrawlProfileService) UpdateProfile(id uint, input services.CrawlProfileInput) (*models.CrawlProfile, error) {
+	if _, exists := m.profiles[id]; !exists {
+		return nil, services.ErrProfileNotFound
+	}
+	return m.CreateProfile(input)
+}
+
+func (m *mockCrawlProfileService) DeleteProfile(id uint) error {
+	if _, exists := m.profiles[id]; !exists {
+		return services.ErrProfileNotFound
+	}
+	delete(m.profiles, id)
+	return nil
+}
+
+func setupCrawlProfileRouter(service services.CrawlProfileService) *gin.Engine {
+	handler := NewCrawlProfileHandler(service)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/crawl-profiles", handler.CreateProfile)
+	router.GET("/crawl-profiles/:id", handler.GetProfile)
+	router.DELETE("/crawl-profiles/:id", handler.DeleteProfile)
+	return router
+}
+
+func TestCreateCrawlProfile_Success(t *testing.T) {
+	mockService := &mockCrawlProfileService{profiles: make(map[uint]*models.CrawlProfile)}
+	router := setupCrawlProfileRouter(mockService)
+
+	jsonBody, _ := json.Marshal(CrawlProfileRequest{
+		Name:              "staging",
+		UserAgent:         "Mozilla/5.0",
+		Cookies:           map[string]string{"session": "t0ken"},
+		BasicAuthUsername: "admin",
+		BasicAuthPassword: "s3cret",
+		TimeoutSeconds:    60,
+	})
+	req := httptest.NewRequest(http.MethodPost, "/crawl-profiles", bytes.NewReader(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+	if mockService.input.BasicAuthPassword != "s3cret" || mockService.input.TimeoutSeconds != 60 {
+		t.Errorf("Expected the request to be passed to the service, got %+v", mockService.input)
+	}
+	if strings.Contains(w.Body.String(), "s3cret") || strings.Contains(w.Body.String(), "basic_auth_password") {
+		t.Errorf("Expected the password not to be returned, got %s", w.Body.String())
+	}
+	if strings.Contains(w.Body.String(), "t0ken") || !strings.Contains(w.Body.String(), `"cookie_names":["session"]`) {
+		t.Errorf("Expected only the cookie names to be returned, got %s", w.Body.String())
+	}
+}
+
+func TestCreateCrawlProfile_Invalid(t *testing.T) {
+	mockService := &mockCrawlProfileService{profiles: make(map[uint]*models.CrawlProfile)}
+	router := setupCrawlProfileRouter(mockService)
+
+	bodies := []string{
+		`{"user_agent": "Mozilla/5.0"}`,
+		`{"name": "staging", "timeout_seconds": 301}`,
+		`{"name": "staging", "headers": {"Cookie": "session=abc"}}`,
+	}
+	for _, body := range bodies {
+		req := httptest.NewRequest(http.MethodPost, "/crawl-profiles", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status %d, got %d", body, http.StatusBadRequest, w.Code)
+		}
+	}
+}
+
+func TestCrawlProfile_NotFound(t *testing.T) {
+	mockService := &mockCrawlProfileService{profiles: make(map[uint]*models.CrawlProfile)}
+	router := setupCrawlProfileRouter(mockService)
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		req := httptest.NewRequest(method, "/crawl-profiles/42", nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusNotFound {
+			t.Errorf("%s: expected status %d, got %d", method, http.StatusNotFound, w.Code)
+		}
+	}
+}
@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	stdErrors "errors"
+	"net/http"
+	"strconv"
+	"sykell-crawler/internal/errors"
+	"sykell-crawler/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ExtractionRuleHandler struct {
+	ruleService services.ExtractionRuleService
+}
+
+func NewExtractionRuleHandler(ruleService services.ExtractionRuleService) *ExtractionRuleHandler {
+	return &ExtractionRuleHandler{ruleService: ruleService}
+}
+
+type ExtractionRuleRequest struct {
+	Name      string `json:"name" binding:"required,max=64"`
+	Selector  string `json:"selector" binding:"required,max=512"`
+	Mode      string `json:"mode" binding:"required,oneof=text attribute count"`
+	Attribute string `json:"attribute" binding:"max=64"`
+	URLID     *uint  `json:"url_id"`
+	Group     string `json:"group" binding:"max=64"`
+}
+
+func (r ExtractionRuleRequest) input() services.ExtractionRuleInput {
+	return services.ExtractionRuleInput{
+		Name:      r.Name,
+		Selector:  r.Selector,
+		Mode:      r.Mode,
+		Attribute: r.Attribute,
+		URLID:     r.URLID,
+		Group:     r.Group,
+	}
+}
+
+func (h *ExtractionRuleHandler) CreateRule(c *gin.Context) {
+	var req ExtractionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.RespondWithError(c, errors.ValidationError(err.Error()))
+		return
+	}
+
+	rule, err := h.ruleService.CreateRule(req.input())
+	if err != nil {
+		respondWithRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, rule)
+}
+
+func (h *ExtractionRuleHandler) GetRules(c *gin.Context) {
+	var urlID uint
+	if value := c.Query("url_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			errors.RespondWithError(c, errors.ValidationError("Invalid url_id"))
+			return
+		}
+		urlID = uint(parsed)
+	}
+
+	rules, err := h.ruleService.ListRules(urlID, c.Query("group"))
+	if err != nil {
+		errors.RespondWithStandardError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"rules": rules})
+}
+
+func (h *ExtractionRuleHandler) UpdateRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errors.RespondWithError(c, errors.ValidationError("Invalid rule ID"))
+		return
+	}
+
+	var req ExtractionRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.RespondWithError(c, errors.ValidationError(err.Error()))
+		return
+	}
+
+	rule, err := h.ruleService.UpdateRule(uint(id), req.input())
+	if err != nil {
+		respondWithRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, rule)
+}
+
+func (h *ExtractionRuleHandler) DeleteRule(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errors.RespondWithError(c, errors.ValidationError("Invalid rule ID"))
+		return
+	}
+
+	if err := h.ruleService.DeleteRule(uint(id)); err != nil {
+		respondWithRuleError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"message": "Extraction rule deleted"})
+}
+
+func respondWithRuleError(c *gin.Context, err error) {
+	switch {
+	case stdErrors.Is(err, services.ErrRuleNotFound):
+		errors.RespondWithError(c, errors.NotFoundError(err.Error()))
+	case stdErrors.Is(err, services.ErrInvalidRule):
+		errors.RespondWithError(c, errors.ValidationError(err.Error()))
+	default:
+		errors.RespondWithStandardError(c, err)
+	}
+}
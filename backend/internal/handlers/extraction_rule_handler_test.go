@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sykell-crawler/internal/models"
+	"sykell-crawler/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockExtractionRuleService struct {
+	rules map[uint]*models.ExtractionRule
+	input services.ExtractionRuleInput
+}
+
+func (m *mockExtractionRuleService) CreateRule(input services.ExtractionRuleInput) (*models.ExtractionRule, error) {
+	m.input = input
+	if input.URLID == nil && input.Group == "" {
+		return nil, fmt.Errorf("%w: exactly one of url_id and group is required", services.ErrInvalidRule)
+	}
+	rule := &models.ExtractionRule{ID: 1, Name: input.Name, Selector: input.Selector, Mode: input.Mode, Group: input.Group}
+	m.rules[rule.ID] = rule
+	return rule, nil
+}
+
+func (m *mockExtractionRuleService) ListRules(urlID uint, group string) ([]*models.ExtractionRule, error) {
+	var rules []*models.ExtractionRule
+	for _, rule := range m.rules {
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+func (m *mockExtractionRuleService) UpdateRule(id uint, input services.ExtractionRuleInput) (*models.ExtractionRule, error) {
+	if _, exists := m.rules[id]; !exists {
+		return nil, services.ErrRuleNotFound
+	}
+	return m.CreateRule(input)
+}
+
+func (m *mockExtractionRuleService) DeleteRule(id uint) error {
+	if _, exists := m.rules[id]; !exists {
+		return services.ErrRuleNotFound
+	}
+	delete(m.rules, id)
+	return nil
+}
+
+func TestCreateExtractionRule_Success(t *testing.T) {
+	mockService := &mockExtractionRuleService{rules: make(map[uint]*models.ExtractionRule)}
+	handler := NewExtractionRuleHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/extraction-rules", handler.CreateRule)
+
+	jsonBody, _ := json.Marshal(ExtractionRuleRequest{Name: "price", Selector: ".price", Mode: "text", Group: "products"})
+	req := httptest.NewRequest(http.MethodPost, "/extraction-rules", bytes.NewReader(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Fatalf("Expected status %d, got %d", http.StatusCreated, w.Code)
+	}
+
+	var response models.ExtractionRule
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Name != "price" || response.Group != "products" {
+		t.Errorf("Unexpected response %+v", response)
+	}
+}
+
+func TestCreateExtractionRule_Invalid(t *testing.T) {
+	mockService := &mockExtractionRuleService{rules: make(map[uint]*models.ExtractionRule)}
+	handler := NewExtractionRuleHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/extraction-rules", handler.CreateRule)
+
+	for _, body := range []string{
+		`{"name":"price","selector":".price","mode":"html","group":"products"}`,
+		`{"name":"price","selector":".price","mode":"text"}`,
+	} {
+		req := httptest.NewRequest(http.MethodPost, "/extraction-rules", bytes.NewReader([]byte(body)))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusBadRequest, body, w.Code)
+		}
+	}
+}
+
+func TestDeleteExtractionRule_NotFound(t *testing.T) {
+	handler := NewExtractionRuleHandler(&mockExtractionRuleService{rules: make(map[uint]*models.ExtractionRule)})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.DELETE("/extraction-rules/:id", handler.DeleteRule)
+
+	req := httptest.NewRequest(http.MethodDelete, "/extraction-rules/7", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
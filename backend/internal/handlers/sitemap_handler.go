@@ -0,0 +1,42 @@
+package handlers
+
+import (
+	stdErrors "errors"
+	"net/http"
+	"sykell-crawler/internal/errors"
+	"sykell-crawler/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SitemapHandler struct {
+	sitemapService services.SitemapService
+}
+
+func NewSitemapHandler(sitemapService services.SitemapService) *SitemapHandler {
+	return &SitemapHandler{sitemapService: sitemapService}
+}
+
+type ImportSitemapRequest struct {
+	Domain string `json:"domain" binding:"required"`
+}
+
+func (h *SitemapHandler) ImportSitemap(c *gin.Context) {
+	var req ImportSitemapRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.RespondWithError(c, errors.ValidationError(err.Error()))
+		return
+	}
+
+	result, err := h.sitemapService.ImportSitemaps(req.Domain)
+	if err != nil {
+		if stdErrors.Is(err, services.ErrNoSitemap) {
+			errors.RespondWithError(c, errors.NotFoundError(err.Error()))
+			return
+		}
+		errors.RespondWithError(c, errors.ValidationError(err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
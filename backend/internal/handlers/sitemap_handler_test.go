@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sykell-crawler/internal/services"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+type mockSitemapService struct {
+	result *services.SitemapImportResult
+	err    error
+}
+
+func (m *mockSitemapService) ImportSitemaps(domain string) (*services.SitemapImportResult, error) {
+	return m.result, m.err
+}
+
+func TestImportSitemap_Success(t *testing.T) {
+	handler := NewSitemapHandler(&mockSitemapService{
+		result: &services.SitemapImportResult{Added: 3, Existing: 1},
+	})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/urls/import-sitemap", handler.ImportSitemap)
+
+	jsonBody, _ := json.Marshal(ImportSitemapRequest{Domain: "example.com"})
+	req := httptest.NewRequest(http.MethodPost, "/urls/import-sitemap", bytes.NewReader(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response services.SitemapImportResult
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Errorf("Failed to unmarshal response: %v", err)
+	}
+	if response.Added != 3 || response.Existing != 1 {
+		t.Errorf("Expected 3 added and 1 existing, got %d and %d", response.Added, response.Existing)
+	}
+}
+
+func TestImportSitemap_NotFound(t *testing.T) {
+	handler := NewSitemapHandler(&mockSitemapService{err: services.ErrNoSitemap})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.POST("/urls/import-sitemap", handler.ImportSitemap)
+
+	jsonBody, _ := json.Marshal(ImportSitemapRequest{Domain: "example.com"})
+	req := httptest.NewRequest(http.MethodPost, "/urls/import-sitemap", bytes.NewReader(jsonBody))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
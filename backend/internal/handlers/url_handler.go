@@ -1,9 +1,13 @@
 package handlers
 
 import (
+	stdErrors "errors"
+	"fmt"
 	"net/http"
 	"strconv"
 	"sykell-crawler/internal/errors"
+	"sykell-crawler/internal/models"
+	"sykell-crawler/internal/repositories"
 	"sykell-crawler/internal/services"
 
 	"github.com/gin-gonic/gin"
@@ -18,7 +22,24 @@ func NewURLHandler(urlService services.URLService) *URLHandler {
 }
 
 type AddURLRequest struct {
-	URL string `json:"url" binding:"required"`
+	URL            string          `json:"url" binding:"required"`
+	Mode           string          `json:"mode" binding:"omitempty,oneof=page site"`
+	MaxDepth       int             `json:"max_depth" binding:"omitempty,min=1"`
+	MaxPages       int             `json:"max_pages" binding:"omitempty,min=1"`
+	IgnoreRobots   bool            `json:"ignore_robots"`
+	ScopeMode      string          `json:"scope_mode" binding:"omitempty,oneof=host subdomains domain"`
+	ScopeHosts     []string        `json:"scope_hosts"`
+	Group          string          `json:"group" binding:"max=64"`
+	Analyzers      map[string]bool `json:"analyzers"`
+	CrawlProfileID *uint           `json:"crawl_profile_id"`
+}
+
+type UpdateURLSettingsRequest struct {
+	ScopeMode      *string          `json:"scope_mode" binding:"omitempty,oneof=host subdomains domain"`
+	ScopeHosts     *[]string        `json:"scope_hosts"`
+	Group          *string          `json:"group" binding:"omitempty,max=64"`
+	Analyzers      *map[string]bool `json:"analyzers"`
+	CrawlProfileID *uint            `json:"crawl_profile_id"`
 }
 
 type BulkActionRequest struct {
@@ -33,6 +54,29 @@ type URLListResponse struct {
 	Limit int         `json:"limit"`
 }
 
+type LinkListResponse struct {
+	Links []*models.Link `json:"links"`
+	Total int64          `json:"total"`
+	Page  int            `json:"page"`
+	Limit int            `json:"limit"`
+}
+
+type DuplicateListResponse struct {
+	Clusters []services.DuplicateCluster `json:"clusters"`
+	Total    int64                       `json:"total"`
+	Page     int                         `json:"page"`
+	Limit    int                         `json:"limit"`
+}
+
+// linkCheckStatuses are the check_status values the links endpoint filters by.
+var linkCheckStatuses = map[string]bool{
+	models.LinkOK:        true,
+	models.LinkBroken:    true,
+	models.LinkBlocked:   true,
+	models.LinkUnchecked: true,
+	models.LinkInvalid:   true,
+}
+
 func (h *URLHandler) AddURL(c *gin.Context) {
 	var req AddURLRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -40,7 +84,17 @@ func (h *URLHandler) AddURL(c *gin.Context) {
 		return
 	}
 
-	result, err := h.urlService.AddURL(req.URL)
+	result, err := h.urlService.AddURL(req.URL, services.AddURLOptions{
+		Mode:           models.CrawlMode(req.Mode),
+		MaxDepth:       req.MaxDepth,
+		MaxPages:       req.MaxPages,
+		IgnoreRobots:   req.IgnoreRobots,
+		ScopeMode:      models.ScopeMode(req.ScopeMode),
+		ScopeHosts:     req.ScopeHosts,
+		Group:          req.Group,
+		Analyzers:      req.Analyzers,
+		CrawlProfileID: req.CrawlProfileID,
+	})
 	if err != nil {
 		errors.RespondWithError(c, errors.ValidationError(err.Error()))
 		return
@@ -66,6 +120,41 @@ func (h *URLHandler) GetURL(c *gin.Context) {
 	c.JSON(http.StatusOK, url)
 }
 
+func (h *URLHandler) UpdateURLSettings(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errors.RespondWithError(c, errors.ValidationError("Invalid URL ID"))
+		return
+	}
+
+	var req UpdateURLSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		errors.RespondWithError(c, errors.ValidationError(err.Error()))
+		return
+	}
+
+	settings := services.URLSettings{ScopeHosts: req.ScopeHosts, Group: req.Group, Analyzers: req.Analyzers, CrawlProfileID: req.CrawlProfileID}
+	if req.ScopeMode != nil {
+		mode := models.ScopeMode(*req.ScopeMode)
+		settings.ScopeMode = &mode
+	}
+
+	url, err := h.urlService.UpdateSettings(uint(id), settings)
+	if err != nil {
+		switch {
+		case stdErrors.Is(err, services.ErrURLNotFound):
+			errors.RespondWithError(c, errors.NotFoundError("URL not found"))
+		case stdErrors.Is(err, services.ErrInvalidSettings):
+			errors.RespondWithError(c, errors.ValidationError(err.Error()))
+		default:
+			errors.RespondWithStandardError(c, err)
+		}
+		return
+	}
+
+	c.JSON(http.StatusOK, url)
+}
+
 func (h *URLHandler) GetAllURLs(c *gin.Context) {
 	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
 	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "10"))
@@ -122,4 +211,119 @@ func (h *URLHandler) BulkAction(c *gin.Context) {
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "Action completed successfully"})
-}
\ No newline at end of file
+}
+
+// GetURLLinks lists the links found on a URL's latest crawl, with optional
+// filters and pagination.
+func (h *URLHandler) GetURLLinks(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		errors.RespondWithError(c, errors.ValidationError("Invalid URL ID"))
+		return
+	}
+
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	filter := repositories.LinkFilter{
+		Scheme:      c.Query("scheme"),
+		CheckStatus: c.Query("status"),
+		Rel:         c.Query("rel"),
+		Search:      c.Query("search"),
+	}
+	if resultID := c.Query("result_id"); resultID != "" {
+		parsed, err := strconv.ParseUint(resultID, 10, 32)
+		if err != nil {
+			errors.RespondWithError(c, errors.ValidationError("Invalid result_id"))
+			return
+		}
+		filter.ResultID = uint(parsed)
+	}
+	if internal := c.Query("internal"); internal != "" {
+		parsed, err := strconv.ParseBool(internal)
+		if err != nil {
+			errors.RespondWithError(c, errors.ValidationError("internal must be true or false"))
+			return
+		}
+		filter.Internal = &parsed
+	}
+	if filter.CheckStatus != "" && !linkCheckStatuses[filter.CheckStatus] {
+		errors.RespondWithError(c, errors.ValidationError("Invalid status"))
+		return
+	}
+	if filter.Rel != "" && filter.Rel != "nofollow" && filter.Rel != "sponsored" && filter.Rel != "ugc" {
+		errors.RespondWithError(c, errors.ValidationError("rel must be nofollow, sponsored or ugc"))
+		return
+	}
+
+	links, total, err := h.urlService.GetLinks(uint(id), filter, page, limit)
+	if err != nil {
+		if stdErrors.Is(err, services.ErrURLNotFound) {
+			errors.RespondWithError(c, errors.NotFoundError("URL not found"))
+			return
+		}
+		errors.RespondWithStandardError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, LinkListResponse{
+		Links: links,
+		Total: total,
+		Page:  page,
+		Limit: limit,
+	})
+}
+
+func (h *URLHandler) GetDuplicates(c *gin.Context) {
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	limit, _ := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 20
+	}
+
+	opts := services.DuplicateOptions{
+		Field:     c.Query("field"),
+		Kind:      c.Query("kind"),
+		Threshold: services.DefaultNearDuplicateThreshold,
+	}
+	switch opts.Field {
+	case "", services.DuplicateTitle, services.DuplicateMetaDescription, services.DuplicateContent:
+	default:
+		errors.RespondWithError(c, errors.ValidationError("field must be title, meta_description or content"))
+		return
+	}
+	if opts.Kind != "" && opts.Kind != services.DuplicateExact && opts.Kind != services.DuplicateNear {
+		errors.RespondWithError(c, errors.ValidationError("kind must be exact or near"))
+		return
+	}
+	if threshold := c.Query("threshold"); threshold != "" {
+		parsed, err := strconv.Atoi(threshold)
+		if err != nil || parsed < 1 || parsed > services.MaxNearDuplicateThreshold {
+			errors.RespondWithError(c, errors.ValidationError(fmt.Sprintf("threshold must be between 1 and %d", services.MaxNearDuplicateThreshold)))
+			return
+		}
+		opts.Threshold = parsed
+	}
+
+	clusters, total, err := h.urlService.GetDuplicates(opts, page, limit)
+	if err != nil {
+		errors.RespondWithStandardError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, DuplicateListResponse{
+		Clusters: clusters,
+		Total:    total,
+		Page:     page,
+		Limit:    limit,
+	})
+}
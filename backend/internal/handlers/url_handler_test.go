@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"sykell-crawler/internal/models"
+	"sykell-crawler/internal/repositories"
 	"sykell-crawler/internal/services"
 	"testing"
 
@@ -14,13 +15,17 @@ import (
 )
 
 type mockURLService struct {
-	urls    map[uint]*models.URL
-	nextID  uint
-	failGet bool
-	failAdd bool
+	urls       map[uint]*models.URL
+	nextID     uint
+	failGet    bool
+	failAdd    bool
+	links      []*models.Link
+	linkFilter repositories.LinkFilter
+	duplicates []services.DuplicateCluster
+	dupOptions services.DuplicateOptions
 }
 
-func (m *mockURLService) AddURL(url string) (*services.AddURLResult, error) {
+func (m *mockURLService) AddURL(url string, opts services.AddURLOptions) (*services.AddURLResult, error) {
 	if m.failAdd {
 		return nil, errors.New("failed to add URL")
 	}
@@ -75,6 +80,33 @@ func (m *mockURLService) RecrawlURLs(ids []uint) error {
 	return nil
 }
 
+func (m *mockURLService) GetLinks(id uint, filter repositories.LinkFilter, page, limit int) ([]*models.Link, int64, error) {
+	if _, exists := m.urls[id]; !exists {
+		return nil, 0, services.ErrURLNotFound
+	}
+	m.linkFilter = filter
+	return m.links, int64(len(m.links)), nil
+}
+
+func (m *mockURLService) GetDuplicates(opts services.DuplicateOptions, page, limit int) ([]services.DuplicateCluster, int64, error) {
+	m.dupOptions = opts
+	return m.duplicates, int64(len(m.duplicates)), nil
+}
+
+func (m *mockURLService) UpdateSettings(id uint, settings services.URLSettings) (*models.URL, error) {
+	url, exists := m.urls[id]
+	if !exists {
+		return nil, services.ErrURLNotFound
+	}
+	if settings.ScopeMode != nil {
+		url.ScopeMode = *settings.ScopeMode
+	}
+	if settings.ScopeHosts != nil {
+		url.ScopeHosts = *settings.ScopeHosts
+	}
+	return url, nil
+}
+
 func TestNewURLHandler(t *testing.T) {
 	handler := NewURLHandler(nil)
 	if handler == nil {
@@ -254,3 +286,189 @@ func TestGetURL_NotFound(t *testing.T) {
 		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
 	}
 }
+
+func TestGetURLLinks_Success(t *testing.T) {
+	mockService := &mockURLService{
+		urls:  map[uint]*models.URL{1: {ID: 1, URL: "https://example.com"}},
+		links: []*models.Link{{ID: 1, URL: "https://example.com/about", Internal: true, Scheme: "https", CheckStatus: models.LinkOK}},
+	}
+	handler := NewURLHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/urls/:id/links", handler.GetURLLinks)
+
+	req := httptest.NewRequest(http.MethodGet, "/urls/1/links?internal=true&status=ok&rel=nofollow&scheme=https", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response LinkListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Total != 1 || len(response.Links) != 1 || response.Limit != 50 {
+		t.Errorf("Unexpected response %+v", response)
+	}
+
+	filter := mockService.linkFilter
+	if filter.Internal == nil || !*filter.Internal || filter.CheckStatus != models.LinkOK || filter.Rel != "nofollow" || filter.Scheme != "https" {
+		t.Errorf("Expected filters to be passed to the service, got %+v", filter)
+	}
+}
+
+func TestGetURLLinks_InvalidFilter(t *testing.T) {
+	mockService := &mockURLService{
+		urls: map[uint]*models.URL{1: {ID: 1, URL: "https://example.com"}},
+	}
+	handler := NewURLHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/urls/:id/links", handler.GetURLLinks)
+
+	for _, query := range []string{"internal=maybe", "status=gone", "rel=noopener"} {
+		req := httptest.NewRequest(http.MethodGet, "/urls/1/links?"+query, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusBadRequest, query, w.Code)
+		}
+	}
+}
+
+func TestGetURLLinks_NotFound(t *testing.T) {
+	mockService := &mockURLService{
+		urls: make(map[uint]*models.URL),
+	}
+	handler := NewURLHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/urls/:id/links", handler.GetURLLinks)
+
+	req := httptest.NewRequest(http.MethodGet, "/urls/999/links", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status %d, got %d", http.StatusNotFound, w.Code)
+	}
+}
+
+func TestUpdateURLSettings_Success(t *testing.T) {
+	mockService := &mockURLService{
+		urls: map[uint]*models.URL{1: {ID: 1, URL: "https://example.com", ScopeMode: models.ScopeHost, ScopeHosts: []string{"cdn.example.net"}}},
+	}
+	handler := NewURLHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PATCH("/urls/:id", handler.UpdateURLSettings)
+
+	req := httptest.NewRequest(http.MethodPatch, "/urls/1", bytes.NewReader([]byte(`{"scope_mode":"domain"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response models.URL
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.ScopeMode != models.ScopeDomain {
+		t.Errorf("Expected scope mode 'domain', got '%s'", response.ScopeMode)
+	}
+	if len(response.ScopeHosts) != 1 {
+		t.Errorf("Expected scope hosts to be left unchanged, got %v", response.ScopeHosts)
+	}
+}
+
+func TestUpdateURLSettings_InvalidScopeMode(t *testing.T) {
+	mockService := &mockURLService{
+		urls: map[uint]*models.URL{1: {ID: 1, URL: "https://example.com"}},
+	}
+	handler := NewURLHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.PATCH("/urls/:id", handler.UpdateURLSettings)
+
+	req := httptest.NewRequest(http.MethodPatch, "/urls/1", bytes.NewReader([]byte(`{"scope_mode":"everything"}`)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status %d, got %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestGetDuplicates_Success(t *testing.T) {
+	mockService := &mockURLService{
+		duplicates: []services.DuplicateCluster{{
+			Field: services.DuplicateTitle,
+			Kind:  services.DuplicateExact,
+			Value: "Red Shoes",
+			URLs:  []services.DuplicateURL{{ID: 1, URL: "https://example.com/a"}, {ID: 2, URL: "https://example.com/b"}},
+		}},
+	}
+	handler := NewURLHandler(mockService)
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/urls/duplicates", handler.GetDuplicates)
+
+	req := httptest.NewRequest(http.MethodGet, "/urls/duplicates?field=content&kind=near&threshold=5", nil)
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status %d, got %d", http.StatusOK, w.Code)
+	}
+
+	var response DuplicateListResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Failed to unmarshal response: %v", err)
+	}
+	if response.Total != 1 || len(response.Clusters) != 1 || len(response.Clusters[0].URLs) != 2 || response.Limit != 20 {
+		t.Errorf("Unexpected response %+v", response)
+	}
+
+	opts := mockService.dupOptions
+	if opts.Field != services.DuplicateContent || opts.Kind != services.DuplicateNear || opts.Threshold != 5 {
+		t.Errorf("Expected options to be passed to the service, got %+v", opts)
+	}
+}
+
+func TestGetDuplicates_InvalidOptions(t *testing.T) {
+	handler := NewURLHandler(&mockURLService{})
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/urls/duplicates", handler.GetDuplicates)
+
+	for _, query := range []string{"field=h1", "kind=fuzzy", "threshold=0", "threshold=64", "threshold=few"} {
+		req := httptest.NewRequest(http.MethodGet, "/urls/duplicates?"+query, nil)
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d for %s, got %d", http.StatusBadRequest, query, w.Code)
+		}
+	}
+}
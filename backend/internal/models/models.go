@@ -14,40 +14,157 @@ const (
 	StatusDone    CrawlStatus = "done"
 	StatusError   CrawlStatus = "error"
 	StatusStopped CrawlStatus = "stopped"
+	// StatusUnsupported means the URL was fetched but is not an HTML page
+	StatusUnsupported CrawlStatus = "unsupported"
+)
+
+type CrawlMode string
+
+const (
+	CrawlModePage CrawlMode = "page"
+	CrawlModeSite CrawlMode = "site"
+)
+
+// ScopeMode decides which hosts count as part of a URL's site. A URL's
+// ScopeHosts are added to any mode; "*.example.net" there also includes the
+// subdomains of example.net.
+type ScopeMode string
+
+const (
+	// ScopeHost is only the URL's host, with or without www.
+	ScopeHost ScopeMode = "host"
+	// ScopeSubdomains is the URL's host and all of its subdomains
+	ScopeSubdomains ScopeMode = "subdomains"
+	// ScopeDomain is every host under the URL's registrable domain, per the
+	// public suffix list
+	ScopeDomain ScopeMode = "domain"
 )
 
 type URL struct {
-	ID           uint           `json:"id" gorm:"primaryKey"`
-	URL          string         `json:"url" gorm:"unique;not null;index"`
-	Title        string         `json:"title"`
-	Status       CrawlStatus    `json:"status" gorm:"default:queued"`
-	ErrorMessage string         `json:"error_message,omitempty"`
-	CreatedAt    time.Time      `json:"created_at"`
-	UpdatedAt    time.Time      `json:"updated_at"`
-	DeletedAt    gorm.DeletedAt `json:"-" gorm:"index"`
-	Results      []CrawlResult  `json:"results,omitempty" gorm:"foreignKey:URLID"`
+	ID             uint            `json:"id" gorm:"primaryKey"`
+	URL            string          `json:"url" gorm:"unique;not null;index"`
+	Title          string          `json:"title"`
+	Status         CrawlStatus     `json:"status" gorm:"default:queued"`
+	ErrorMessage   string          `json:"error_message,omitempty"`
+	CrawlMode      CrawlMode       `json:"crawl_mode" gorm:"default:page"`
+	MaxDepth       int             `json:"max_depth,omitempty"`
+	MaxPages       int             `json:"max_pages,omitempty"`
+	IgnoreRobots   bool            `json:"ignore_robots"`
+	ScopeMode      ScopeMode       `json:"scope_mode" gorm:"size:16;default:host"`
+	ScopeHosts     []string        `json:"scope_hosts,omitempty" gorm:"type:text;serializer:json"`
+	Group          string          `json:"group,omitempty" gorm:"column:group_name;size:64;index"`
+	Analyzers      map[string]bool `json:"analyzers,omitempty" gorm:"type:text;serializer:json"`
+	CrawlProfileID *uint           `json:"crawl_profile_id,omitempty" gorm:"index"`
+	CrawlProfile   *CrawlProfile   `json:"crawl_profile,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt  `json:"-" gorm:"index"`
+	Results        []CrawlResult   `json:"results,omitempty" gorm:"foreignKey:URLID"`
+}
+
+// CrawlProfile is a named set of request settings used for every request made
+// while crawling the URLs assigned to it. Cookies and basic-auth credentials
+// are only sent to the host of the crawled URL. BasicAuthPassword holds the
+// encrypted password and is never serialized. A zero TimeoutSeconds keeps the
+// configured HTTP timeout.
+type CrawlProfile struct {
+	ID                 uint              `json:"id" gorm:"primaryKey"`
+	Name               string            `json:"name" gorm:"size:64;not null;index"`
+	UserAgent          string            `json:"user_agent,omitempty" gorm:"size:512"`
+	Headers            map[string]string `json:"headers,omitempty" gorm:"type:text;serializer:json"`
+	Cookies            map[string]string `json:"-" gorm:"type:text;serializer:json"`
+	CookieNames        []string          `json:"cookie_names,omitempty" gorm:"type:text;serializer:json"`
+	BasicAuthUsername  string            `json:"basic_auth_username,omitempty" gorm:"size:255"`
+	BasicAuthPassword  string            `json:"-" gorm:"type:text"`
+	TimeoutSeconds     int               `json:"timeout_seconds"`
+	SkipLinkChecks     bool              `json:"skip_link_checks"`
+	SkipImageChecks    bool              `json:"skip_image_checks"`
+	SkipResourceChecks bool              `json:"skip_resource_checks"`
+	CreatedAt          time.Time         `json:"created_at"`
+	UpdatedAt          time.Time         `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt    `json:"-" gorm:"index"`
 }
 
 type CrawlResult struct {
-	ID             uint           `json:"id" gorm:"primaryKey"`
-	URLID          uint           `json:"url_id" gorm:"not null;uniqueIndex"`
-	HTMLVersion    string         `json:"html_version"`
-	Title          string         `json:"title"`
-	H1Count        int            `json:"h1_count"`
-	H2Count        int            `json:"h2_count"`
-	H3Count        int            `json:"h3_count"`
-	H4Count        int            `json:"h4_count"`
-	H5Count        int            `json:"h5_count"`
-	H6Count        int            `json:"h6_count"`
-	InternalLinks  int            `json:"internal_links"`
-	ExternalLinks  int            `json:"external_links"`
-	BrokenLinks    int            `json:"broken_links"`
-	HasLoginForm   bool           `json:"has_login_form"`
-	ErrorMessage   string         `json:"error_message,omitempty"`
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      time.Time      `json:"updated_at"`
-	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
-	BrokenURLs     []BrokenURL    `json:"broken_urls,omitempty" gorm:"foreignKey:CrawlResultID"`
+	ID                 uint           `json:"id" gorm:"primaryKey"`
+	URLID              uint           `json:"url_id" gorm:"not null;index"`
+	ParentID           *uint          `json:"parent_id,omitempty" gorm:"index"`
+	PageURL            string         `json:"page_url,omitempty"`
+	FinalURL           string         `json:"final_url,omitempty"`
+	RedirectCount      int            `json:"redirect_count"`
+	ContentType        string         `json:"content_type,omitempty"`
+	Charset            string         `json:"charset,omitempty"`
+	UnsupportedContent bool           `json:"unsupported_content"`
+	TLSCertStatus      string         `json:"tls_cert_status,omitempty" gorm:"size:32"`
+	TLSCertExpiresAt   *time.Time     `json:"tls_cert_expires_at,omitempty"`
+	DNSLookupMs        int64          `json:"dns_lookup_ms"`
+	ConnectMs          int64          `json:"connect_ms"`
+	TLSHandshakeMs     int64          `json:"tls_handshake_ms"`
+	TTFBMs             int64          `json:"ttfb_ms"`
+	DownloadMs         int64          `json:"download_ms"`
+	TotalMs            int64          `json:"total_ms"`
+	ResponseBytes      int64          `json:"response_bytes"`
+	UncompressedBytes  int64          `json:"uncompressed_bytes"`
+	Depth              int            `json:"depth"`
+	PagesCrawled       int            `json:"pages_crawled,omitempty"`
+	HTMLVersion        string         `json:"html_version"`
+	DoctypePublicID    string         `json:"doctype_public_id,omitempty"`
+	DoctypeSystemID    string         `json:"doctype_system_id,omitempty"`
+	Title              string         `json:"title"`
+	H1Count            int            `json:"h1_count"`
+	H2Count            int            `json:"h2_count"`
+	H3Count            int            `json:"h3_count"`
+	H4Count            int            `json:"h4_count"`
+	H5Count            int            `json:"h5_count"`
+	H6Count            int            `json:"h6_count"`
+	HeadingIssues      []HeadingIssue `json:"heading_issues,omitempty" gorm:"type:text;serializer:json"`
+	InternalLinks      int            `json:"internal_links"`
+	ExternalLinks      int            `json:"external_links"`
+	BrokenLinks        int            `json:"broken_links"`
+	// HasLoginForm is true when one of Forms is a login form
+	HasLoginForm                bool                     `json:"has_login_form"`
+	BlockedByRobots             bool                     `json:"blocked_by_robots"`
+	BlockedLinks                int                      `json:"blocked_links"`
+	Images                      int                      `json:"images"`
+	ImagesMissingAlt            int                      `json:"images_missing_alt"`
+	BrokenImages                int                      `json:"broken_images"`
+	BlockedImages               int                      `json:"blocked_images"`
+	BrokenResources             int                      `json:"broken_resources"`
+	BlockedResources            int                      `json:"blocked_resources"`
+	ActiveMixedContent          int                      `json:"active_mixed_content"`
+	PassiveMixedContent         int                      `json:"passive_mixed_content"`
+	WordCount                   int                      `json:"word_count"`
+	TextRatio                   float64                  `json:"text_ratio"`
+	ContentLanguage             string                   `json:"content_language,omitempty" gorm:"size:16;index"`
+	Readability                 *float64                 `json:"readability,omitempty"`
+	LangMismatch                bool                     `json:"lang_mismatch"`
+	ContentSimHash              string                   `json:"content_simhash,omitempty" gorm:"size:16;index"`
+	ContentHash                 string                   `json:"content_hash,omitempty" gorm:"size:64;index"`
+	TitleHash                   string                   `json:"title_hash,omitempty" gorm:"size:64;index"`
+	MetaDescriptionHash         string                   `json:"meta_description_hash,omitempty" gorm:"size:64;index"`
+	AccessibilityViolationCount int                      `json:"accessibility_violation_count"`
+	AccessibilityRuleCounts     map[string]int           `json:"accessibility_rule_counts,omitempty" gorm:"type:text;serializer:json"`
+	ErrorMessage                string                   `json:"error_message,omitempty"`
+	CreatedAt                   time.Time                `json:"created_at"`
+	UpdatedAt                   time.Time                `json:"updated_at"`
+	DeletedAt                   gorm.DeletedAt           `json:"-" gorm:"index"`
+	BrokenURLs                  []BrokenURL              `json:"broken_urls,omitempty" gorm:"foreignKey:CrawlResultID"`
+	BlockedURLs                 []BlockedURL             `json:"blocked_urls,omitempty" gorm:"foreignKey:CrawlResultID"`
+	Links                       []Link                   `json:"links,omitempty" gorm:"foreignKey:CrawlResultID"`
+	Redirects                   []RedirectHop            `json:"redirects,omitempty" gorm:"foreignKey:CrawlResultID"`
+	Headings                    []Heading                `json:"headings,omitempty" gorm:"foreignKey:CrawlResultID"`
+	Forms                       []AuthForm               `json:"forms,omitempty" gorm:"foreignKey:CrawlResultID"`
+	BrokenImageURLs             []BrokenImage            `json:"broken_image_urls,omitempty" gorm:"foreignKey:CrawlResultID"`
+	Resources                   []Resource               `json:"resources,omitempty" gorm:"foreignKey:CrawlResultID"`
+	MixedContent                []MixedContent           `json:"mixed_content,omitempty" gorm:"foreignKey:CrawlResultID"`
+	AccessibilityViolations     []AccessibilityViolation `json:"accessibility_violations,omitempty" gorm:"foreignKey:CrawlResultID"`
+	ExtractedValues             []ExtractedValue         `json:"extracted_values,omitempty" gorm:"foreignKey:CrawlResultID"`
+	Findings                    []Finding                `json:"findings,omitempty" gorm:"foreignKey:CrawlResultID"`
+	SEO                         *SEOMetadata             `json:"seo,omitempty" gorm:"foreignKey:CrawlResultID"`
+	SecurityAudit               *SecurityAudit           `json:"security_audit,omitempty" gorm:"foreignKey:CrawlResultID"`
+	TLS                         *TLSInfo                 `json:"tls,omitempty" gorm:"foreignKey:CrawlResultID"`
+	StructuredData              []StructuredDataItem     `json:"structured_data,omitempty" gorm:"foreignKey:CrawlResultID"`
+	Pages                       []CrawlResult            `json:"pages,omitempty" gorm:"foreignKey:ParentID"`
 }
 
 type BrokenURL struct {
@@ -61,6 +178,408 @@ type BrokenURL struct {
 	DeletedAt      gorm.DeletedAt `json:"-" gorm:"index"`
 }
 
+// Kinds of target a BlockedURL was found as.
+const (
+	BlockedLink     = "link"
+	BlockedImage    = "image"
+	BlockedResource = "resource"
+)
+
+// BlockedURL is a link, image or other subresource that wasn't checked
+// because robots.txt disallows it.
+type BlockedURL struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	CrawlResultID uint           `json:"crawl_result_id" gorm:"not null;index"`
+	Kind          string         `json:"kind" gorm:"size:16;default:link"`
+	URL           string         `json:"url" gorm:"not null"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Link schemes other than http and https that are recorded but not checked.
+// A fragment link points into the page itself, e.g. href="#top".
+const (
+	LinkSchemeMailto     = "mailto"
+	LinkSchemeTel        = "tel"
+	LinkSchemeJavaScript = "javascript"
+	LinkSchemeFragment   = "fragment"
+)
+
+// Outcomes of checking a link.
+const (
+	LinkOK        = "ok"
+	LinkBroken    = "broken"
+	LinkBlocked   = "blocked"
+	LinkUnchecked = "unchecked"
+	LinkInvalid   = "invalid"
+)
+
+// Link is an anchor found on a page, in document order. Every anchor is kept,
+// so the same URL can appear more than once; it is only checked once. Links
+// are not loaded with their crawl result but listed through their own endpoint.
+type Link struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	CrawlResultID uint           `json:"crawl_result_id" gorm:"not null;index"`
+	Position      int            `json:"position"`
+	URL           string         `json:"url" gorm:"type:text"`
+	AnchorText    string         `json:"anchor_text" gorm:"type:text"`
+	Rel           string         `json:"rel,omitempty"`
+	Nofollow      bool           `json:"nofollow"`
+	Sponsored     bool           `json:"sponsored"`
+	UGC           bool           `json:"ugc"`
+	Target        string         `json:"target,omitempty" gorm:"size:64"`
+	Internal      bool           `json:"internal"`
+	Scheme        string         `json:"scheme" gorm:"size:32;index"`
+	CheckStatus   string         `json:"check_status" gorm:"size:16;index"`
+	StatusCode    int            `json:"status_code,omitempty"`
+	ErrorMessage  string         `json:"error_message,omitempty" gorm:"type:text"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// RedirectHop is one redirect response followed while fetching a page.
+// Position starts at 1 for the response to the requested URL.
+type RedirectHop struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	CrawlResultID uint           `json:"crawl_result_id" gorm:"not null;index"`
+	Position      int            `json:"position"`
+	URL           string         `json:"url" gorm:"not null"`
+	StatusCode    int            `json:"status_code"`
+	Location      string         `json:"location"`
+	DurationMs    int64          `json:"duration_ms"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Heading is one entry of a page's heading outline, in document order.
+// Position starts at 1.
+type Heading struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	CrawlResultID uint           `json:"crawl_result_id" gorm:"not null;index"`
+	Position      int            `json:"position"`
+	Level         int            `json:"level"`
+	Text          string         `json:"text" gorm:"type:text"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Problems found in a page's heading outline.
+const (
+	HeadingMissingH1    = "missing_h1"
+	HeadingMultipleH1   = "multiple_h1"
+	HeadingSkippedLevel = "skipped_level"
+	HeadingEmpty        = "empty_heading"
+)
+
+// HeadingIssue is a problem with the heading outline. Position refers to the
+// offending heading and is 0 for issues about the page as a whole.
+type HeadingIssue struct {
+	Code     string `json:"code"`
+	Position int    `json:"position,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Kinds of forms the crawler recognises.
+const (
+	FormLogin         = "login"
+	FormSignup        = "signup"
+	FormPasswordReset = "password_reset"
+)
+
+// AuthForm is a login, signup or password reset form found on a page.
+// Confidence is the classifier's score between 0 and 1. Action and Method are
+// empty for password fields that are not inside a <form> element.
+type AuthForm struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	CrawlResultID uint           `json:"crawl_result_id" gorm:"not null;index"`
+	Position      int            `json:"position"`
+	Kind          string         `json:"kind" gorm:"size:32;index"`
+	Confidence    float64        `json:"confidence"`
+	Action        string         `json:"action" gorm:"type:text"`
+	Method        string         `json:"method" gorm:"size:16"`
+	Fields        []string       `json:"fields" gorm:"type:text;serializer:json"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Accessibility rules checked on every page, named after the matching axe-core
+// rules. axe-core has no rule for a missing banner, navigation or contentinfo
+// landmark, so those follow the name of landmark-one-main.
+const (
+	AccessibilityHTMLLang    = "html-has-lang"
+	AccessibilityLabel       = "label"
+	AccessibilityImageAlt    = "image-alt"
+	AccessibilityLinkName    = "link-name"
+	AccessibilityButtonName  = "button-name"
+	AccessibilityDuplicateID = "duplicate-id"
+	AccessibilityLandmark    = "landmark-one-main"
+	AccessibilityBanner      = "landmark-banner"
+	AccessibilityNavigation  = "landmark-navigation"
+	AccessibilityContentInfo = "landmark-contentinfo"
+	AccessibilityARIARole    = "aria-roles"
+)
+
+// AccessibilityViolation is one element that fails an accessibility rule.
+// Selector is a CSS selector path to the element.
+type AccessibilityViolation struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	CrawlResultID uint           `json:"crawl_result_id" gorm:"not null;index"`
+	Rule          string         `json:"rule" gorm:"size:32;index"`
+	Selector      string         `json:"selector" gorm:"type:text"`
+	Message       string         `json:"message" gorm:"type:text"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// What an extraction rule reads from the elements its selector matches.
+const (
+	ExtractText      = "text"
+	ExtractAttribute = "attribute"
+	ExtractCount     = "count"
+)
+
+// ExtractionRule is a user-defined CSS selector evaluated on every crawl of
+// the URL it belongs to, or of every URL in its group. Exactly one of URLID
+// and Group is set.
+type ExtractionRule struct {
+	ID        uint           `json:"id" gorm:"primaryKey"`
+	Name      string         `json:"name" gorm:"size:64;not null"`
+	Selector  string         `json:"selector" gorm:"size:512;not null"`
+	Mode      string         `json:"mode" gorm:"size:16;not null"`
+	Attribute string         `json:"attribute,omitempty" gorm:"size:64"`
+	URLID     *uint          `json:"url_id,omitempty" gorm:"index"`
+	Group     string         `json:"group,omitempty" gorm:"column:group_name;size:64;index"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// ExtractedValue is the value an extraction rule produced on a crawled page.
+// Matches is the number of elements the selector matched; Value is the text
+// or attribute of the first match, or the number of matches for count rules.
+type ExtractedValue struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	CrawlResultID uint           `json:"crawl_result_id" gorm:"not null;index"`
+	RuleID        uint           `json:"rule_id" gorm:"index"`
+	Name          string         `json:"name" gorm:"size:64"`
+	Mode          string         `json:"mode" gorm:"size:16"`
+	Value         string         `json:"value" gorm:"type:text"`
+	Matches       int            `json:"matches"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Severities of analyzer findings.
+const (
+	FindingInfo    = "info"
+	FindingWarning = "warning"
+	FindingError   = "error"
+)
+
+// Finding is something an analyzer reported about a crawled page. Type is
+// defined by the analyzer, and Data holds whatever details it attaches.
+type Finding struct {
+	ID            uint                   `json:"id" gorm:"primaryKey"`
+	CrawlResultID uint                   `json:"crawl_result_id" gorm:"not null;index"`
+	Analyzer      string                 `json:"analyzer" gorm:"size:64;index"`
+	Type          string                 `json:"type" gorm:"size:64;index"`
+	Severity      string                 `json:"severity" gorm:"size:16;index"`
+	Message       string                 `json:"message" gorm:"type:text"`
+	Data          map[string]interface{} `json:"data,omitempty" gorm:"type:text;serializer:json"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt         `json:"-" gorm:"index"`
+}
+
+// BrokenImage is an image source on a crawled page that failed to load.
+type BrokenImage struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	CrawlResultID uint           `json:"crawl_result_id" gorm:"not null;index"`
+	URL           string         `json:"url" gorm:"not null"`
+	StatusCode    int            `json:"status_code"`
+	ErrorMessage  string         `json:"error_message,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Kinds of subresources a page loads.
+const (
+	ResourceScript     = "script"
+	ResourceStylesheet = "stylesheet"
+	ResourceFont       = "font"
+	ResourceIcon       = "icon"
+	ResourceIframe     = "iframe"
+	ResourceMedia      = "media"
+	ResourceManifest   = "manifest"
+	ResourceObject     = "object"
+	ResourcePreload    = "preload"
+)
+
+// Resource is a subresource referenced by a crawled page, such as a script or
+// stylesheet, together with the outcome of checking it.
+type Resource struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	CrawlResultID uint           `json:"crawl_result_id" gorm:"not null;index"`
+	Kind          string         `json:"kind" gorm:"size:32;index"`
+	URL           string         `json:"url" gorm:"not null"`
+	StatusCode    int            `json:"status_code"`
+	Broken        bool           `json:"broken"`
+	ErrorMessage  string         `json:"error_message,omitempty"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// Kinds of mixed content. Browsers block active mixed content, which can
+// read or change the page, and upgrade or block passive content such as
+// images, which can only change how the page looks.
+const (
+	MixedContentActive  = "active"
+	MixedContentPassive = "passive"
+)
+
+// MixedContent is an http:// subresource or form action on a page served
+// over HTTPS. Element and Attribute locate the reference, e.g. img and srcset.
+type MixedContent struct {
+	ID            uint           `json:"id" gorm:"primaryKey"`
+	CrawlResultID uint           `json:"crawl_result_id" gorm:"not null;index"`
+	Position      int            `json:"position"`
+	Kind          string         `json:"kind" gorm:"size:16;index"`
+	Element       string         `json:"element" gorm:"size:32"`
+	Attribute     string         `json:"attribute" gorm:"size:32"`
+	URL           string         `json:"url" gorm:"type:text"`
+	CreatedAt     time.Time      `json:"created_at"`
+	UpdatedAt     time.Time      `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// SEOMetadata holds the search and social metadata declared in a page's head.
+type SEOMetadata struct {
+	ID              uint           `json:"id" gorm:"primaryKey"`
+	CrawlResultID   uint           `json:"crawl_result_id" gorm:"not null;index"`
+	MetaDescription string         `json:"meta_description" gorm:"type:text"`
+	MetaRobots      string         `json:"meta_robots"`
+	Canonical       string         `json:"canonical" gorm:"type:text"`
+	Viewport        string         `json:"viewport"`
+	Lang            string         `json:"lang"`
+	OpenGraph       []MetaTag      `json:"open_graph" gorm:"type:text;serializer:json"`
+	TwitterCard     []MetaTag      `json:"twitter_card" gorm:"type:text;serializer:json"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `json:"-" gorm:"index"`
+}
+
+// MetaTag is a single property/content pair such as og:title. Tags are kept
+// as a list because properties like og:image may legitimately repeat.
+type MetaTag struct {
+	Property string `json:"property"`
+	Content  string `json:"content"`
+}
+
+// Grades given to a security header check.
+const (
+	SecurityPass = "pass"
+	SecurityWarn = "warn"
+	SecurityFail = "fail"
+)
+
+// SecurityAudit grades the security-relevant response headers of a crawled
+// page. Headers holds every response header, with cookie values redacted.
+type SecurityAudit struct {
+	ID            uint                `json:"id" gorm:"primaryKey"`
+	CrawlResultID uint                `json:"crawl_result_id" gorm:"not null;index"`
+	Headers       map[string][]string `json:"headers" gorm:"type:text;serializer:json"`
+	Checks        []SecurityCheck     `json:"checks" gorm:"type:text;serializer:json"`
+	Passed        int                 `json:"passed"`
+	Warnings      int                 `json:"warnings"`
+	Failures      int                 `json:"failures"`
+	CreatedAt     time.Time           `json:"created_at"`
+	UpdatedAt     time.Time           `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt      `json:"-" gorm:"index"`
+}
+
+// SecurityCheck is the grade given to one header, or to one cookie for
+// Set-Cookie.
+type SecurityCheck struct {
+	Header  string `json:"header"`
+	Value   string `json:"value,omitempty"`
+	Grade   string `json:"grade"`
+	Message string `json:"message"`
+}
+
+// Outcomes of inspecting a page's TLS certificate, from worst to best.
+const (
+	TLSCertExpired          = "expired"
+	TLSCertHostnameMismatch = "hostname_mismatch"
+	TLSCertUntrusted        = "untrusted"
+	TLSCertNotYetValid      = "not_yet_valid"
+	TLSCertExpiringSoon     = "expiring_soon"
+	TLSCertValid            = "valid"
+)
+
+// TLSInfo describes the TLS connection a page was served over. Certificates
+// holds the peer chain starting with the leaf; Status is the most severe of
+// the Issues found with it.
+type TLSInfo struct {
+	ID            uint              `json:"id" gorm:"primaryKey"`
+	CrawlResultID uint              `json:"crawl_result_id" gorm:"not null;index"`
+	Version       string            `json:"version"`
+	CipherSuite   string            `json:"cipher_suite"`
+	ServerName    string            `json:"server_name"`
+	Status        string            `json:"status" gorm:"size:32"`
+	Issues        []string          `json:"issues,omitempty" gorm:"type:text;serializer:json"`
+	Certificates  []CertificateInfo `json:"certificates" gorm:"type:text;serializer:json"`
+	CreatedAt     time.Time         `json:"created_at"`
+	UpdatedAt     time.Time         `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt    `json:"-" gorm:"index"`
+}
+
+// CertificateInfo is one certificate of a peer chain.
+type CertificateInfo struct {
+	Subject            string    `json:"subject"`
+	Issuer             string    `json:"issuer"`
+	DNSNames           []string  `json:"dns_names,omitempty"`
+	IPAddresses        []string  `json:"ip_addresses,omitempty"`
+	SerialNumber       string    `json:"serial_number"`
+	NotBefore          time.Time `json:"not_before"`
+	NotAfter           time.Time `json:"not_after"`
+	KeyType            string    `json:"key_type"`
+	SignatureAlgorithm string    `json:"signature_algorithm"`
+	IsCA               bool      `json:"is_ca"`
+}
+
+// Structured data syntaxes recognised on crawled pages.
+const (
+	StructuredDataJSONLD    = "json-ld"
+	StructuredDataMicrodata = "microdata"
+	StructuredDataRDFa      = "rdfa"
+)
+
+// StructuredDataItem is a top-level schema.org item found on a page. Type is
+// the item's first type with any vocabulary prefix removed, e.g. "Product".
+// Errors lists JSON that failed to parse and required properties that are
+// missing; Valid is true when there are none.
+type StructuredDataItem struct {
+	ID            uint                   `json:"id" gorm:"primaryKey"`
+	CrawlResultID uint                   `json:"crawl_result_id" gorm:"not null;index"`
+	Format        string                 `json:"format" gorm:"size:16"`
+	Type          string                 `json:"type" gorm:"size:128;index"`
+	Properties    map[string]interface{} `json:"properties,omitempty" gorm:"type:mediumtext;serializer:json"`
+	Valid         bool                   `json:"valid"`
+	Errors        []string               `json:"errors,omitempty" gorm:"type:text;serializer:json"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     time.Time              `json:"updated_at"`
+	DeletedAt     gorm.DeletedAt         `json:"-" gorm:"index"`
+}
+
 type User struct {
 	ID        uint           `json:"id" gorm:"primaryKey"`
 	Username  string         `json:"username" gorm:"unique;not null"`
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"sykell-crawler/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type CrawlProfileRepository interface {
+	Create(profile *models.CrawlProfile) error
+	GetByID(id uint) (*models.CrawlProfile, error)
+	GetByName(name string) (*models.CrawlProfile, error)
+	GetAll() ([]*models.CrawlProfile, error)
+	Update(profile *models.CrawlProfile) error
+	Delete(id uint) error
+}
+
+type crawlProfileRepository struct {
+	db *gorm.DB
+}
+
+func NewCrawlProfileRepository(db *gorm.DB) CrawlProfileRepository {
+	return &crawlProfileRepository{db: db}
+}
+
+func (r *crawlProfileRepository) Create(profile *models.CrawlProfile) error {
+	return r.db.Create(profile).Error
+}
+
+func (r *crawlProfileRepository) GetByID(id uint) (*models.CrawlProfile, error) {
+	var profile models.CrawlProfile
+	err := r.db.First(&profile, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *crawlProfileRepository) GetByName(name string) (*models.CrawlProfile, error) {
+	var profile models.CrawlProfile
+	err := r.db.Where("name = ?", name).First(&profile).Error
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (r *crawlProfileRepository) GetAll() ([]*models.CrawlProfile, error) {
+	var profiles []*models.CrawlProfile
+	err := r.db.Order("name").Find(&profiles).Error
+	return profiles, err
+}
+
+func (r *crawlProfileRepository) Update(profile *models.CrawlProfile) error {
+	return r.db.Save(profile).Error
+}
+
+// Delete removes a profile and unassigns it from its URLs, which fall back to
+// the default request settings.
+func (r *crawlProfileRepository) Delete(id uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.URL{}).Where("crawl_profile_id = ?", id).Update("crawl_profile_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.CrawlProfile{}, id).Error
+	})
+}
@@ -0,0 +1,39 @@
+package repositories
+
+import (
+	"sykell-crawler/internal/models"
+	"testing"
+)
+
+func TestCrawlProfileRepository_Delete(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewCrawlProfileRepository(db)
+	urlRepo := NewURLRepository(db)
+
+	profile := &models.CrawlProfile{Name: "staging", UserAgent: "Mozilla/5.0", Headers: map[string]string{"Accept-Language": "de"}}
+	if err := repo.Create(profile); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	url := &models.URL{URL: "https://staging.example.com", CrawlProfileID: &profile.ID}
+	db.Create(url)
+
+	loaded, err := urlRepo.GetByID(url.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if loaded.CrawlProfile == nil || loaded.CrawlProfile.Headers["Accept-Language"] != "de" {
+		t.Fatalf("Expected the URL's profile to be loaded, got %+v", loaded.CrawlProfile)
+	}
+
+	if err := repo.Delete(profile.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := repo.GetByName("staging"); err == nil {
+		t.Error("Expected the deleted profile not to be found")
+	}
+
+	loaded, _ = urlRepo.GetByID(url.ID)
+	if loaded.CrawlProfileID != nil || loaded.CrawlProfile != nil {
+		t.Errorf("Expected the profile to be unassigned, got %v", loaded.CrawlProfileID)
+	}
+}
@@ -15,6 +15,66 @@ type CrawlResultRepository interface {
 	Delete(id uint) error
 }
 
+// resultDetails are the associations loaded alongside every crawl result,
+// with the order to load them in where it matters. Links are left out as
+// there can be thousands; they are paged through URLRepository.GetLinks.
+var resultDetails = []struct {
+	association string
+	order       string
+}{
+	{"BrokenURLs", ""},
+	{"BlockedURLs", ""},
+	{"Redirects", "position ASC"},
+	{"Headings", "position ASC"},
+	{"Forms", "position ASC"},
+	{"BrokenImageURLs", ""},
+	{"Resources", ""},
+	{"MixedContent", "position ASC"},
+	{"AccessibilityViolations", ""},
+	{"ExtractedValues", ""},
+	{"Findings", ""},
+	{"SEO", ""},
+	{"SecurityAudit", ""},
+	{"TLS", ""},
+	{"StructuredData", ""},
+}
+
+// resultDetailModels are the per-result records replaced on every recrawl.
+var resultDetailModels = []interface{}{
+	&models.BrokenURL{},
+	&models.BlockedURL{},
+	&models.Link{},
+	&models.RedirectHop{},
+	&models.Heading{},
+	&models.AuthForm{},
+	&models.BrokenImage{},
+	&models.Resource{},
+	&models.MixedContent{},
+	&models.AccessibilityViolation{},
+	&models.ExtractedValue{},
+	&models.Finding{},
+	&models.SEOMetadata{},
+	&models.SecurityAudit{},
+	&models.TLSInfo{},
+	&models.StructuredDataItem{},
+}
+
+// preloadResultDetails preloads every result detail association under prefix,
+// e.g. "Results." when loading a URL.
+func preloadResultDetails(db *gorm.DB, prefix string) *gorm.DB {
+	for _, detail := range resultDetails {
+		if detail.order == "" {
+			db = db.Preload(prefix + detail.association)
+			continue
+		}
+		order := detail.order
+		db = db.Preload(prefix+detail.association, func(db *gorm.DB) *gorm.DB {
+			return db.Order(order)
+		})
+	}
+	return db
+}
+
 type crawlResultRepository struct {
 	db *gorm.DB
 }
@@ -29,7 +89,9 @@ func (r *crawlResultRepository) Create(result *models.CrawlResult) error {
 
 func (r *crawlResultRepository) GetByURLID(urlID uint) (*models.CrawlResult, error) {
 	var result models.CrawlResult
-	err := r.db.Preload("BrokenURLs").Where("url_id = ?", urlID).First(&result).Error
+	db := preloadResultDetails(r.db, "")
+	db = preloadResultDetails(db, "Pages.")
+	err := db.Where("url_id = ? AND parent_id IS NULL", urlID).First(&result).Error
 	if err != nil {
 		return nil, err
 	}
@@ -38,8 +100,9 @@ func (r *crawlResultRepository) GetByURLID(urlID uint) (*models.CrawlResult, err
 
 func (r *crawlResultRepository) GetLatestByURLID(urlID uint) (*models.CrawlResult, error) {
 	var result models.CrawlResult
-	err := r.db.Preload("BrokenURLs").
-		Where("url_id = ?", urlID).
+	db := preloadResultDetails(r.db, "")
+	db = preloadResultDetails(db, "Pages.")
+	err := db.Where("url_id = ? AND parent_id IS NULL", urlID).
 		Order("created_at DESC").
 		First(&result).Error
 	if err != nil {
@@ -55,7 +118,7 @@ func (r *crawlResultRepository) Update(result *models.CrawlResult) error {
 func (r *crawlResultRepository) Upsert(result *models.CrawlResult) error {
 	// Check if a result already exists for this URL
 	var existing models.CrawlResult
-	err := r.db.Where("url_id = ?", result.URLID).First(&existing).Error
+	err := r.db.Where("url_id = ? AND parent_id IS NULL", result.URLID).First(&existing).Error
 	
 	if err != nil {
 		if err == gorm.ErrRecordNotFound {
@@ -65,10 +128,24 @@ func (r *crawlResultRepository) Upsert(result *models.CrawlResult) error {
 		return err
 	}
 	
-	// Delete old broken URLs before updating
-	if err := r.db.Where("crawl_result_id = ?", existing.ID).Delete(&models.BrokenURL{}).Error; err != nil {
+	// Delete old result details before updating
+	if err := r.deleteResultDetails([]uint{existing.ID}); err != nil {
+		return err
+	}
+
+	// Delete pages from the previous site crawl along with their details
+	var pageIDs []uint
+	if err := r.db.Model(&models.CrawlResult{}).Where("parent_id = ?", existing.ID).Pluck("id", &pageIDs).Error; err != nil {
 		return err
 	}
+	if len(pageIDs) > 0 {
+		if err := r.deleteResultDetails(pageIDs); err != nil {
+			return err
+		}
+		if err := r.db.Delete(&models.CrawlResult{}, pageIDs).Error; err != nil {
+			return err
+		}
+	}
 	
 	// Update existing result with new data
 	result.ID = existing.ID // Keep the same ID
@@ -77,6 +154,15 @@ func (r *crawlResultRepository) Upsert(result *models.CrawlResult) error {
 	return r.db.Save(result).Error
 }
 
+func (r *crawlResultRepository) deleteResultDetails(resultIDs []uint) error {
+	for _, model := range resultDetailModels {
+		if err := r.db.Where("crawl_result_id IN ?", resultIDs).Delete(model).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (r *crawlResultRepository) Delete(id uint) error {
 	return r.db.Delete(&models.CrawlResult{}, id).Error
 }
\ No newline at end of file
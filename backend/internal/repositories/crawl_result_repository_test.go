@@ -14,7 +14,7 @@ func setupTestCrawlResultDB(t *testing.T) *gorm.DB {
 		t.Fatalf("Failed to create in-memory database: %v", err)
 	}
 
-	err = db.AutoMigrate(&models.URL{}, &models.CrawlResult{}, &models.BrokenURL{})
+	err = db.AutoMigrate(&models.CrawlProfile{}, &models.URL{}, &models.CrawlResult{}, &models.BrokenURL{}, &models.BlockedURL{}, &models.Link{}, &models.RedirectHop{}, &models.Heading{}, &models.AuthForm{}, &models.BrokenImage{}, &models.Resource{}, &models.MixedContent{}, &models.AccessibilityViolation{}, &models.ExtractedValue{}, &models.Finding{}, &models.ExtractionRule{}, &models.SEOMetadata{}, &models.SecurityAudit{}, &models.TLSInfo{}, &models.StructuredDataItem{})
 	if err != nil {
 		t.Fatalf("Failed to migrate tables: %v", err)
 	}
@@ -292,4 +292,142 @@ func TestCrawlResultRepository_GetLatestByURLID(t *testing.T) {
 	if latest.Title != "First Result" {
 		t.Errorf("Expected latest result title 'First Result', got '%s'", latest.Title)
 	}
-}
\ No newline at end of file
+}
+
+func TestCrawlResultRepository_UpsertReplacesSitePages(t *testing.T) {
+	db := setupTestCrawlResultDB(t)
+	repo := NewCrawlResultRepository(db)
+
+	original := &models.CrawlResult{
+		URLID: 1,
+		Title: "Home",
+		Pages: []models.CrawlResult{
+			{URLID: 1, PageURL: "https://example.com/a", Depth: 1, Title: "A"},
+			{URLID: 1, PageURL: "https://example.com/b", Depth: 1, Title: "B",
+				BrokenURLs: []models.BrokenURL{{URL: "https://example.com/missing", StatusCode: 404}}},
+		},
+	}
+	if err := repo.Upsert(original); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	recrawled := &models.CrawlResult{
+		URLID: 1,
+		Title: "Home",
+		Pages: []models.CrawlResult{
+			{URLID: 1, PageURL: "https://example.com/c", Depth: 1, Title: "C"},
+		},
+	}
+	if err := repo.Upsert(recrawled); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	retrieved, err := repo.GetByURLID(1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if retrieved.ParentID != nil {
+		t.Error("Expected the seed result to be returned, got a child page")
+	}
+	if len(retrieved.Pages) != 1 {
+		t.Fatalf("Expected 1 page after recrawl, got %d", len(retrieved.Pages))
+	}
+	if retrieved.Pages[0].Title != "C" {
+		t.Errorf("Expected page 'C', got '%s'", retrieved.Pages[0].Title)
+	}
+
+	var brokenCount int64
+	db.Model(&models.BrokenURL{}).Count(&brokenCount)
+	if brokenCount != 0 {
+		t.Errorf("Expected broken URLs of old pages to be removed, got %d", brokenCount)
+	}
+}
+
+func TestCrawlResultRepository_UpsertWithSEOMetadata(t *testing.T) {
+	db := setupTestCrawlResultDB(t)
+	repo := NewCrawlResultRepository(db)
+
+	result := &models.CrawlResult{
+		URLID: 1,
+		Title: "Home",
+		SEO: &models.SEOMetadata{
+			MetaDescription: "Old description",
+			OpenGraph:       []models.MetaTag{{Property: "og:title", Content: "Home"}},
+		},
+	}
+	if err := repo.Upsert(result); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	recrawled := &models.CrawlResult{
+		URLID: 1,
+		Title: "Home",
+		SEO: &models.SEOMetadata{
+			MetaDescription: "New description",
+			Canonical:       "https://example.com/",
+			OpenGraph: []models.MetaTag{
+				{Property: "og:image", Content: "https://example.com/a.png"},
+				{Property: "og:image", Content: "https://example.com/b.png"},
+			},
+		},
+	}
+	if err := repo.Upsert(recrawled); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	retrieved, err := repo.GetByURLID(1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if retrieved.SEO == nil {
+		t.Fatal("Expected SEO metadata to be loaded")
+	}
+	if retrieved.SEO.MetaDescription != "New description" {
+		t.Errorf("Expected 'New description', got '%s'", retrieved.SEO.MetaDescription)
+	}
+	if len(retrieved.SEO.OpenGraph) != 2 || retrieved.SEO.OpenGraph[1].Content != "https://example.com/b.png" {
+		t.Errorf("Expected Open Graph tags to round-trip, got %+v", retrieved.SEO.OpenGraph)
+	}
+
+	var seoCount int64
+	db.Model(&models.SEOMetadata{}).Count(&seoCount)
+	if seoCount != 1 {
+		t.Errorf("Expected old SEO metadata to be replaced, got %d rows", seoCount)
+	}
+}
+
+func TestCrawlResultRepository_UpsertWithStructuredData(t *testing.T) {
+	db := setupTestCrawlResultDB(t)
+	repo := NewCrawlResultRepository(db)
+
+	result := &models.CrawlResult{
+		URLID: 1,
+		StructuredData: []models.StructuredDataItem{
+			{
+				Format:     models.StructuredDataJSONLD,
+				Type:       "Product",
+				Properties: map[string]interface{}{"@type": "Product", "name": "Widget"},
+				Errors:     []string{`Product is missing one of the properties "offers", "review", "aggregateRating"`},
+			},
+		},
+	}
+	if err := repo.Upsert(result); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	retrieved, err := repo.GetByURLID(1)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(retrieved.StructuredData) != 1 {
+		t.Fatalf("Expected 1 structured data item, got %d", len(retrieved.StructuredData))
+	}
+	item := retrieved.StructuredData[0]
+	if item.Properties["name"] != "Widget" {
+		t.Errorf("Expected properties to round-trip, got %v", item.Properties)
+	}
+	if len(item.Errors) != 1 || item.Valid {
+		t.Errorf("Expected the validation error to round-trip, got %+v", item)
+	}
+}
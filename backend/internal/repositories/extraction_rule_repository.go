@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"sykell-crawler/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type ExtractionRuleRepository interface {
+	Create(rule *models.ExtractionRule) error
+	GetByID(id uint) (*models.ExtractionRule, error)
+	GetAll(urlID uint, group string) ([]*models.ExtractionRule, error)
+	GetForURL(url *models.URL) ([]*models.ExtractionRule, error)
+	Update(rule *models.ExtractionRule) error
+	Delete(id uint) error
+}
+
+type extractionRuleRepository struct {
+	db *gorm.DB
+}
+
+func NewExtractionRuleRepository(db *gorm.DB) ExtractionRuleRepository {
+	return &extractionRuleRepository{db: db}
+}
+
+func (r *extractionRuleRepository) Create(rule *models.ExtractionRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *extractionRuleRepository) GetByID(id uint) (*models.ExtractionRule, error) {
+	var rule models.ExtractionRule
+	err := r.db.First(&rule, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// GetAll lists rules, optionally only those of a URL or a group.
+func (r *extractionRuleRepository) GetAll(urlID uint, group string) ([]*models.ExtractionRule, error) {
+	var rules []*models.ExtractionRule
+	query := r.db.Order("id")
+	if urlID != 0 {
+		query = query.Where("url_id = ?", urlID)
+	}
+	if group != "" {
+		query = query.Where("group_name = ?", group)
+	}
+	err := query.Find(&rules).Error
+	return rules, err
+}
+
+// GetForURL returns the rules that apply to a URL: its own rules and the
+// rules of its group.
+func (r *extractionRuleRepository) GetForURL(url *models.URL) ([]*models.ExtractionRule, error) {
+	var rules []*models.ExtractionRule
+	query := r.db.Where("url_id = ?", url.ID)
+	if url.Group != "" {
+		query = query.Or("group_name = ?", url.Group)
+	}
+	err := query.Order("id").Find(&rules).Error
+	return rules, err
+}
+
+func (r *extractionRuleRepository) Update(rule *models.ExtractionRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *extractionRuleRepository) Delete(id uint) error {
+	return r.db.Delete(&models.ExtractionRule{}, id).Error
+}
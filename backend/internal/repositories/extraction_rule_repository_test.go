@@ -0,0 +1,41 @@
+package repositories
+
+import (
+	"sykell-crawler/internal/models"
+	"testing"
+)
+
+func TestExtractionRuleRepository_GetForURL(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewExtractionRuleRepository(db)
+
+	shoes := &models.URL{URL: "https://shop.example.com/shoes", Group: "products"}
+	db.Create(shoes)
+	other := &models.URL{URL: "https://shop.example.com/about"}
+	db.Create(other)
+
+	repo.Create(&models.ExtractionRule{Name: "price", Selector: ".price", Mode: models.ExtractText, URLID: &shoes.ID})
+	repo.Create(&models.ExtractionRule{Name: "stock", Selector: ".badge", Mode: models.ExtractCount, Group: "products"})
+	repo.Create(&models.ExtractionRule{Name: "team", Selector: ".team", Mode: models.ExtractCount, URLID: &other.ID})
+	deleted := &models.ExtractionRule{Name: "old", Selector: ".old", Mode: models.ExtractCount, Group: "products"}
+	repo.Create(deleted)
+	repo.Delete(deleted.ID)
+
+	rules, err := repo.GetForURL(shoes)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(rules) != 2 || rules[0].Name != "price" || rules[1].Name != "stock" {
+		t.Errorf("Expected the URL's own rule and its group's rule, got %+v", rules)
+	}
+
+	rules, _ = repo.GetForURL(other)
+	if len(rules) != 1 || rules[0].Name != "team" {
+		t.Errorf("Expected only the URL's own rule for a URL without a group, got %+v", rules)
+	}
+
+	rules, _ = repo.GetAll(0, "products")
+	if len(rules) != 1 || rules[0].Name != "stock" {
+		t.Errorf("Expected the group's rules, got %+v", rules)
+	}
+}
@@ -15,9 +15,42 @@ type URLRepository interface {
 	RestoreURL(id uint) error
 	GetAll(offset, limit int, search, sortBy, sortOrder string) ([]*models.URL, int64, error)
 	Update(url *models.URL) error
+	UpdateColumns(url *models.URL, columns ...string) error
 	Delete(id uint) error
 	UpdateStatus(id uint, status models.CrawlStatus) error
 	GetByIDs(ids []uint) ([]*models.URL, error)
+	GetLinks(urlID uint, filter LinkFilter, offset, limit int) ([]*models.Link, int64, error)
+	GetFingerprints() ([]*URLFingerprint, error)
+}
+
+// LinkFilter narrows the links listed by GetLinks. Zero values match all links.
+type LinkFilter struct {
+	ResultID    uint
+	Internal    *bool
+	Scheme      string
+	CheckStatus string
+	Rel         string
+	Search      string
+}
+
+// linkRelColumns are the rel values links can be filtered by.
+var linkRelColumns = map[string]string{
+	"nofollow":  "links.nofollow",
+	"sponsored": "links.sponsored",
+	"ugc":       "links.ugc",
+}
+
+// URLFingerprint holds the hashes of the latest crawl of a URL that
+// duplicates are found by, along with the texts they were computed from.
+type URLFingerprint struct {
+	URLID               uint
+	URL                 string
+	Title               string
+	MetaDescription     string
+	TitleHash           string
+	MetaDescriptionHash string
+	ContentSimHash      string
+	ContentHash         string
 }
 
 type urlRepository struct {
@@ -34,7 +67,14 @@ func (r *urlRepository) Create(url *models.URL) error {
 
 func (r *urlRepository) GetByID(id uint) (*models.URL, error) {
 	var url models.URL
-	err := r.db.Preload("Results.BrokenURLs").First(&url, id).Error
+	db := r.db.Preload("CrawlProfile").
+		Preload("Results", "parent_id IS NULL").
+		Preload("Results.Pages", func(db *gorm.DB) *gorm.DB {
+			return db.Order("depth ASC, id ASC")
+		})
+	db = preloadResultDetails(db, "Results.")
+	db = preloadResultDetails(db, "Results.Pages.")
+	err := db.First(&url, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -68,8 +108,12 @@ func (r *urlRepository) GetAll(offset, limit int, search, sortBy, sortOrder stri
 	var total int64
 
 	baseQuery := r.db.Model(&models.URL{}).
-		Select("urls.*, COALESCE(cr.internal_links, 0) as internal_links, COALESCE(cr.external_links, 0) as external_links, COALESCE(cr.broken_links, 0) as broken_links").
-		Joins("LEFT JOIN crawl_results cr ON urls.id = cr.url_id AND cr.id = (SELECT MAX(cr2.id) FROM crawl_results cr2 WHERE cr2.url_id = urls.id)")
+		Select("urls.*, COALESCE(cr.internal_links, 0) as internal_links, COALESCE(cr.external_links, 0) as external_links, COALESCE(cr.broken_links, 0) as broken_links, " +
+			"COALESCE(cr.dns_lookup_ms, 0) as dns_lookup_ms, COALESCE(cr.connect_ms, 0) as connect_ms, COALESCE(cr.tls_handshake_ms, 0) as tls_handshake_ms, " +
+			"COALESCE(cr.ttfb_ms, 0) as ttfb_ms, COALESCE(cr.download_ms, 0) as download_ms, COALESCE(cr.total_ms, 0) as total_ms, " +
+			"COALESCE(cr.response_bytes, 0) as response_bytes, COALESCE(cr.uncompressed_bytes, 0) as uncompressed_bytes, " +
+			"COALESCE(cr.word_count, 0) as word_count, COALESCE(cr.text_ratio, 0) as text_ratio").
+		Joins("LEFT JOIN crawl_results cr ON urls.id = cr.url_id AND cr.id = (SELECT MAX(cr2.id) FROM crawl_results cr2 WHERE cr2.url_id = urls.id AND cr2.parent_id IS NULL)")
 
 	if search != "" {
 		baseQuery = baseQuery.Where("urls.url LIKE ? OR urls.title LIKE ?", "%"+search+"%", "%"+search+"%")
@@ -82,7 +126,7 @@ func (r *urlRepository) GetAll(offset, limit int, search, sortBy, sortOrder stri
 	orderClause := r.buildOrderClause(sortBy, sortOrder)
 
 	// Execute query with pagination and sorting
-	err := baseQuery.Preload("Results").
+	err := baseQuery.Preload("Results", "parent_id IS NULL").
 		Offset(offset).
 		Limit(limit).
 		Order(orderClause).
@@ -94,14 +138,35 @@ func (r *urlRepository) GetAll(offset, limit int, search, sortBy, sortOrder stri
 func (r *urlRepository) buildOrderClause(sortBy, sortOrder string) string {
 	// Validate sortBy field
 	allowedFields := map[string]string{
-		"url":            "urls.url",
-		"title":          "urls.title",
-		"status":         "urls.status",
-		"created_at":     "urls.created_at",
-		"updated_at":     "urls.updated_at",
-		"internal_links": "internal_links",
-		"external_links": "external_links",
-		"broken_links":   "broken_links",
+		"url":                   "urls.url",
+		"title":                 "urls.title",
+		"status":                "urls.status",
+		"created_at":            "urls.created_at",
+		"updated_at":            "urls.updated_at",
+		"internal_links":        "internal_links",
+		"external_links":        "external_links",
+		"broken_links":          "broken_links",
+		"dns_lookup_ms":         "dns_lookup_ms",
+		"connect_ms":            "connect_ms",
+		"tls_handshake_ms":      "tls_handshake_ms",
+		"ttfb_ms":               "ttfb_ms",
+		"download_ms":           "download_ms",
+		"total_ms":              "total_ms",
+		"response_bytes":        "response_bytes",
+		"uncompressed_bytes":    "uncompressed_bytes",
+		"word_count":            "word_count",
+		"text_ratio":            "text_ratio",
+		"content_language":      "cr.content_language",
+		"lang_mismatch":         "cr.lang_mismatch",
+		"active_mixed_content":  "COALESCE(cr.active_mixed_content, 0)",
+		"passive_mixed_content": "COALESCE(cr.passive_mixed_content, 0)",
+		// Pages without a score always sort last
+		"readability": "cr.readability IS NULL, cr.readability",
+		// Sort by severity rather than alphabetically, worst last
+		"tls_cert_status": "CASE cr.tls_cert_status WHEN 'expired' THEN 5 WHEN 'hostname_mismatch' THEN 4 WHEN 'untrusted' THEN 3 " +
+			"WHEN 'not_yet_valid' THEN 2 WHEN 'expiring_soon' THEN 1 WHEN 'valid' THEN 0 ELSE -1 END",
+		// URLs without a certificate always sort last
+		"tls_cert_expires_at": "cr.tls_cert_expires_at IS NULL, cr.tls_cert_expires_at",
 	}
 
 	field, exists := allowedFields[sortBy]
@@ -121,6 +186,12 @@ func (r *urlRepository) Update(url *models.URL) error {
 	return r.db.Save(url).Error
 }
 
+// UpdateColumns writes only the given columns of url, so it can't overwrite
+// changes a crawl made to the others in the meantime, such as its status.
+func (r *urlRepository) UpdateColumns(url *models.URL, columns ...string) error {
+	return r.db.Model(url).Select(columns).Updates(url).Error
+}
+
 func (r *urlRepository) Delete(id uint) error {
 	return r.db.Delete(&models.URL{}, id).Error
 }
@@ -134,3 +205,58 @@ func (r *urlRepository) GetByIDs(ids []uint) ([]*models.URL, error) {
 	err := r.db.Where("id IN ?", ids).Find(&urls).Error
 	return urls, err
 }
+
+// GetLinks lists the links found by the latest crawl of a URL, including the
+// pages of a site crawl, in page and document order.
+func (r *urlRepository) GetLinks(urlID uint, filter LinkFilter, offset, limit int) ([]*models.Link, int64, error) {
+	var links []*models.Link
+	var total int64
+
+	query := r.db.Model(&models.Link{}).
+		Joins("JOIN crawl_results cr ON cr.id = links.crawl_result_id AND cr.deleted_at IS NULL").
+		Where("cr.url_id = ?", urlID)
+
+	if filter.ResultID != 0 {
+		query = query.Where("links.crawl_result_id = ?", filter.ResultID)
+	}
+	if filter.Internal != nil {
+		query = query.Where("links.internal = ?", *filter.Internal)
+	}
+	if filter.Scheme != "" {
+		query = query.Where("links.scheme = ?", filter.Scheme)
+	}
+	if filter.CheckStatus != "" {
+		query = query.Where("links.check_status = ?", filter.CheckStatus)
+	}
+	if column, ok := linkRelColumns[filter.Rel]; ok {
+		query = query.Where(column+" = ?", true)
+	}
+	if filter.Search != "" {
+		query = query.Where("links.url LIKE ? OR links.anchor_text LIKE ?", "%"+filter.Search+"%", "%"+filter.Search+"%")
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.Order("links.crawl_result_id, links.position").
+		Offset(offset).
+		Limit(limit).
+		Find(&links).Error
+
+	return links, total, err
+}
+
+// GetFingerprints returns the fingerprints of the latest crawl of every URL
+// that has at least one.
+func (r *urlRepository) GetFingerprints() ([]*URLFingerprint, error) {
+	var fingerprints []*URLFingerprint
+	err := r.db.Model(&models.URL{}).
+		Select("urls.id AS url_id, urls.url, cr.title, sm.meta_description, cr.title_hash, cr.meta_description_hash, cr.content_sim_hash, cr.content_hash").
+		Joins("JOIN crawl_results cr ON urls.id = cr.url_id AND cr.id = (SELECT MAX(cr2.id) FROM crawl_results cr2 WHERE cr2.url_id = urls.id AND cr2.parent_id IS NULL AND cr2.deleted_at IS NULL)").
+		Joins("LEFT JOIN seo_metadata sm ON sm.crawl_result_id = cr.id AND sm.deleted_at IS NULL").
+		Where("cr.title_hash <> '' OR cr.meta_description_hash <> '' OR cr.content_sim_hash <> '' OR cr.content_hash <> ''").
+		Order("urls.id").
+		Scan(&fingerprints).Error
+	return fingerprints, err
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"sykell-crawler/internal/models"
 	"testing"
+	"time"
 
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -14,7 +15,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("Failed to create in-memory database: %v", err)
 	}
 
-	err = db.AutoMigrate(&models.URL{}, &models.CrawlResult{}, &models.BrokenURL{})
+	err = db.AutoMigrate(&models.CrawlProfile{}, &models.URL{}, &models.CrawlResult{}, &models.BrokenURL{}, &models.BlockedURL{}, &models.Link{}, &models.RedirectHop{}, &models.Heading{}, &models.AuthForm{}, &models.BrokenImage{}, &models.Resource{}, &models.MixedContent{}, &models.AccessibilityViolation{}, &models.ExtractedValue{}, &models.Finding{}, &models.ExtractionRule{}, &models.SEOMetadata{}, &models.SecurityAudit{}, &models.TLSInfo{}, &models.StructuredDataItem{})
 	if err != nil {
 		t.Fatalf("Failed to migrate tables: %v", err)
 	}
@@ -130,6 +131,33 @@ func TestURLRepository_Update(t *testing.T) {
 	}
 }
 
+func TestURLRepository_UpdateColumns(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewURLRepository(db)
+
+	url := &models.URL{
+		URL:    "https://example.com",
+		Status: models.StatusQueued,
+	}
+	repo.Create(url)
+	stale, _ := repo.GetByID(url.ID)
+	repo.UpdateStatus(url.ID, models.StatusRunning)
+
+	stale.Group = "clients"
+	stale.ScopeHosts = []string{"cdn.example.com"}
+	if err := repo.UpdateColumns(stale, "group_name", "scope_hosts"); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	retrieved, _ := repo.GetByID(url.ID)
+	if retrieved.Group != "clients" || len(retrieved.ScopeHosts) != 1 {
+		t.Errorf("Expected the columns to be updated, got %+v", retrieved)
+	}
+	if retrieved.Status != models.StatusRunning {
+		t.Errorf("Expected the status to be left alone, got '%s'", retrieved.Status)
+	}
+}
+
 func TestURLRepository_Delete(t *testing.T) {
 	db := setupTestDB(t)
 	repo := NewURLRepository(db)
@@ -210,3 +238,228 @@ func TestURLRepository_GetAll_WithSearch(t *testing.T) {
 		t.Errorf("Expected URL 'https://example.com', got '%s'", retrieved[0].URL)
 	}
 }
+
+func TestURLRepository_GetByID_OnlySeedResults(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewURLRepository(db)
+
+	url := &models.URL{
+		URL:       "https://example.com",
+		Status:    models.StatusDone,
+		CrawlMode: models.CrawlModeSite,
+	}
+	repo.Create(url)
+
+	db.Create(&models.CrawlResult{
+		URLID: url.ID,
+		Title: "Home",
+		Pages: []models.CrawlResult{
+			{URLID: url.ID, PageURL: "https://example.com/a", Depth: 1},
+			{URLID: url.ID, PageURL: "https://example.com/b", Depth: 1},
+		},
+	})
+
+	retrieved, err := repo.GetByID(url.ID)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if len(retrieved.Results) != 1 {
+		t.Fatalf("Expected 1 seed result, got %d", len(retrieved.Results))
+	}
+	if len(retrieved.Results[0].Pages) != 2 {
+		t.Errorf("Expected 2 child pages, got %d", len(retrieved.Results[0].Pages))
+	}
+}
+
+func TestURLRepository_GetAll_SortByCertificateExpiry(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewURLRepository(db)
+
+	soon := time.Now().Add(24 * time.Hour)
+	later := time.Now().Add(90 * 24 * time.Hour)
+	fixtures := []struct {
+		url       string
+		expiresAt *time.Time
+	}{
+		{"http://plain.example.com", nil},
+		{"https://later.example.com", &later},
+		{"https://soon.example.com", &soon},
+	}
+	for _, fixture := range fixtures {
+		url := &models.URL{URL: fixture.url, Status: models.StatusDone}
+		if err := repo.Create(url); err != nil {
+			t.Fatalf("Failed to create URL: %v", err)
+		}
+		result := &models.CrawlResult{URLID: url.ID, TLSCertExpiresAt: fixture.expiresAt}
+		if err := db.Create(result).Error; err != nil {
+			t.Fatalf("Failed to create result: %v", err)
+		}
+	}
+
+	retrieved, _, err := repo.GetAll(0, 10, "", "tls_cert_expires_at", "asc")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"https://soon.example.com", "https://later.example.com", "http://plain.example.com"}
+	for i, url := range retrieved {
+		if url.URL != expected[i] {
+			t.Errorf("Expected URL %d to be '%s', got '%s'", i, expected[i], url.URL)
+		}
+	}
+}
+
+func TestURLRepository_GetAll_SortByTTFB(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewURLRepository(db)
+
+	for i, ttfb := range []int64{120, 900, 40} {
+		url := &models.URL{URL: "https://example" + string(rune('a'+i)) + ".com", Status: models.StatusDone}
+		if err := repo.Create(url); err != nil {
+			t.Fatalf("Failed to create URL: %v", err)
+		}
+		if err := db.Create(&models.CrawlResult{URLID: url.ID, TTFBMs: ttfb}).Error; err != nil {
+			t.Fatalf("Failed to create result: %v", err)
+		}
+	}
+
+	retrieved, _, err := repo.GetAll(0, 10, "", "ttfb_ms", "desc")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"https://exampleb.com", "https://examplea.com", "https://examplec.com"}
+	for i, url := range retrieved {
+		if url.URL != expected[i] {
+			t.Errorf("Expected URL %d to be '%s', got '%s'", i, expected[i], url.URL)
+		}
+	}
+}
+
+func TestURLRepository_GetLinks(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewURLRepository(db)
+
+	url := &models.URL{URL: "https://example.com", Status: models.StatusDone}
+	repo.Create(url)
+	other := &models.URL{URL: "https://other.com", Status: models.StatusDone}
+	repo.Create(other)
+
+	db.Create(&models.CrawlResult{
+		URLID: url.ID,
+		Links: []models.Link{
+			{Position: 1, URL: "https://example.com/about", AnchorText: "About", Internal: true, Scheme: "https", CheckStatus: models.LinkOK},
+			{Position: 2, URL: "https://partner.com/", AnchorText: "Partner", Sponsored: true, Scheme: "https", CheckStatus: models.LinkBroken},
+			{Position: 3, URL: "mailto:hi@example.com", AnchorText: "Mail us", Scheme: models.LinkSchemeMailto, CheckStatus: models.LinkUnchecked},
+		},
+		Pages: []models.CrawlResult{
+			{URLID: url.ID, PageURL: "https://example.com/about", Links: []models.Link{
+				{Position: 1, URL: "https://example.com/", AnchorText: "Home", Internal: true, Scheme: "https", CheckStatus: models.LinkOK},
+			}},
+		},
+	})
+	db.Create(&models.CrawlResult{
+		URLID: other.ID,
+		Links: []models.Link{{Position: 1, URL: "https://other.com/", Internal: true, Scheme: "https", CheckStatus: models.LinkOK}},
+	})
+
+	links, total, err := repo.GetLinks(url.ID, LinkFilter{}, 0, 2)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if total != 4 {
+		t.Errorf("Expected 4 links, got %d", total)
+	}
+	if len(links) != 2 || links[0].AnchorText != "About" || links[1].AnchorText != "Partner" {
+		t.Errorf("Expected the first page of links in document order, got %+v", links)
+	}
+
+	internal := true
+	_, total, _ = repo.GetLinks(url.ID, LinkFilter{Internal: &internal}, 0, 10)
+	if total != 2 {
+		t.Errorf("Expected 2 internal links, got %d", total)
+	}
+
+	links, total, _ = repo.GetLinks(url.ID, LinkFilter{Rel: "sponsored", CheckStatus: models.LinkBroken}, 0, 10)
+	if total != 1 || links[0].URL != "https://partner.com/" {
+		t.Errorf("Expected the broken sponsored link, got %+v", links)
+	}
+
+	links, total, _ = repo.GetLinks(url.ID, LinkFilter{Scheme: models.LinkSchemeMailto, Search: "mail"}, 0, 10)
+	if total != 1 || links[0].Scheme != models.LinkSchemeMailto {
+		t.Errorf("Expected the mailto link, got %+v", links)
+	}
+}
+
+func TestURLRepository_GetFingerprints(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewURLRepository(db)
+
+	product := &models.URL{URL: "https://shop.example/shoes", Status: models.StatusDone}
+	repo.Create(product)
+	failed := &models.URL{URL: "https://shop.example/gone", Status: models.StatusError}
+	repo.Create(failed)
+
+	db.Create(&models.CrawlResult{URLID: product.ID, Title: "Old title", TitleHash: "old"})
+	db.Create(&models.CrawlResult{
+		URLID:          product.ID,
+		Title:          "Red Shoes",
+		TitleHash:      "title",
+		ContentSimHash: "00000000000000ff",
+		ContentHash:    "content",
+		SEO:            &models.SEOMetadata{MetaDescription: "Buy red shoes"},
+		Pages: []models.CrawlResult{
+			{URLID: product.ID, PageURL: "https://shop.example/shoes/size-guide", TitleHash: "page"},
+		},
+	})
+	db.Create(&models.CrawlResult{URLID: failed.ID, ErrorMessage: "timeout"})
+
+	fingerprints, err := repo.GetFingerprints()
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(fingerprints) != 1 {
+		t.Fatalf("Expected only the latest crawl of the crawled URL, got %+v", fingerprints)
+	}
+	fp := fingerprints[0]
+	if fp.URLID != product.ID || fp.TitleHash != "title" || fp.ContentSimHash != "00000000000000ff" || fp.ContentHash != "content" || fp.MetaDescription != "Buy red shoes" {
+		t.Errorf("Unexpected fingerprint %+v", fp)
+	}
+}
+
+func TestURLRepository_GetAll_SortByReadability(t *testing.T) {
+	db := setupTestDB(t)
+	repo := NewURLRepository(db)
+
+	easy, hard := 82.5, 31.0
+	fixtures := []struct {
+		url         string
+		readability *float64
+	}{
+		{"https://thin.example.com", nil},
+		{"https://easy.example.com", &easy},
+		{"https://hard.example.com", &hard},
+	}
+	for _, fixture := range fixtures {
+		url := &models.URL{URL: fixture.url, Status: models.StatusDone}
+		if err := repo.Create(url); err != nil {
+			t.Fatalf("Failed to create URL: %v", err)
+		}
+		if err := db.Create(&models.CrawlResult{URLID: url.ID, Readability: fixture.readability}).Error; err != nil {
+			t.Fatalf("Failed to create result: %v", err)
+		}
+	}
+
+	retrieved, _, err := repo.GetAll(0, 10, "", "readability", "desc")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	expected := []string{"https://easy.example.com", "https://hard.example.com", "https://thin.example.com"}
+	for i, url := range retrieved {
+		if url.URL != expected[i] {
+			t.Errorf("Expected URL %d to be '%s', got '%s'", i, expected[i], url.URL)
+		}
+	}
+}
@@ -0,0 +1,351 @@
+package services
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"sykell-crawler/internal/models"
+)
+
+func init() {
+	RegisterAnalyzer(accessibilityAnalyzer{})
+}
+
+// accessibilityAnalyzer fills the accessibility audit of the crawl result.
+type accessibilityAnalyzer struct{}
+
+func (accessibilityAnalyzer) Name() string {
+	return "accessibility"
+}
+
+func (accessibilityAnalyzer) Description() string {
+	return "Audits pages for common accessibility problems"
+}
+
+func (accessibilityAnalyzer) Analyze(input *AnalyzerInput) ([]models.Finding, error) {
+	result := input.Result
+	result.AccessibilityViolations, result.AccessibilityRuleCounts = auditAccessibility(input.Document)
+	for _, count := range result.AccessibilityRuleCounts {
+		result.AccessibilityViolationCount += count
+	}
+	return nil, nil
+}
+
+// maxViolationsPerRule caps how many violations of one rule are stored for a
+// page. The rule counts are not capped.
+const maxViolationsPerRule = 50
+
+// ariaRoles are the non-abstract roles of WAI-ARIA 1.2, the Graphics module
+// and the Digital Publishing module.
+var ariaRoles = toSet(
+	"alert", "alertdialog", "application", "article", "banner", "blockquote", "button", "caption", "cell",
+	"checkbox", "code", "columnheader", "combobox", "complementary", "contentinfo", "definition", "deletion",
+	"dialog", "directory", "document", "emphasis", "feed", "figure", "form", "generic", "grid", "gridcell",
+	"group", "heading", "img", "image", "insertion", "link", "list", "listbox", "listitem", "log", "main", "mark",
+	"marquee", "math", "menu", "menubar", "menuitem", "menuitemcheckbox", "menuitemradio", "meter",
+	"navigation", "none", "note", "option", "paragraph", "presentation", "progressbar", "radio", "radiogroup",
+	"region", "row", "rowgroup", "rowheader", "scrollbar", "search", "searchbox", "separator", "slider",
+	"spinbutton", "status", "strong", "subscript", "superscript", "switch", "tab", "table", "tablist",
+	"tabpanel", "term", "textbox", "time", "timer", "toolbar", "tooltip", "tree", "treegrid", "treeitem",
+	"graphics-document", "graphics-object", "graphics-symbol",
+	"doc-abstract", "doc-acknowledgments", "doc-afterword", "doc-appendix", "doc-backlink", "doc-biblioentry",
+	"doc-bibliography", "doc-biblioref", "doc-chapter", "doc-colophon", "doc-conclusion", "doc-cover",
+	"doc-credit", "doc-credits", "doc-dedication", "doc-endnote", "doc-endnotes", "doc-epigraph",
+	"doc-epilogue", "doc-errata", "doc-example", "doc-footnote", "doc-foreword", "doc-glossary",
+	"doc-glossref", "doc-index", "doc-introduction", "doc-noteref", "doc-notice", "doc-pagebreak",
+	"doc-pagefooter", "doc-pageheader", "doc-pagelist", "doc-part", "doc-preface", "doc-prologue",
+	"doc-pullquote", "doc-qna", "doc-subtitle", "doc-tip", "doc-toc",
+)
+
+// pageLandmarks are the landmarks every page should have. A <header> or
+// <footer> inside sectioning content belongs to that section, so only a
+// scoped element outside all of sectioningElements counts.
+var pageLandmarks = []struct {
+	rule    string
+	role    string
+	element string
+	scoped  bool
+	message string
+}{
+	{models.AccessibilityLandmark, "main", "main", false, "page has no <main> landmark"},
+	{models.AccessibilityBanner, "banner", "header", true, "page has no <header> banner landmark"},
+	{models.AccessibilityNavigation, "navigation", "nav", false, "page has no <nav> landmark"},
+	{models.AccessibilityContentInfo, "contentinfo", "footer", true, "page has no <footer> contentinfo landmark"},
+}
+
+const sectioningElements = "article, aside, main, nav, section"
+
+// unlabeledInputTypes are input types that need no label: they are hidden or
+// labelled by their value or alt text.
+var unlabeledInputTypes = toSet("hidden", "submit", "reset", "button", "image")
+
+// cssIdentifier matches ids that can be used in a selector without escaping.
+var cssIdentifier = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+func toSet(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
+
+// accessibilityAuditor collects the violations found on one page. ids holds
+// the first element with each id, for resolving aria-labelledby.
+type accessibilityAuditor struct {
+	doc        *goquery.Document
+	idCounts   map[string]int
+	ids        map[string]*goquery.Selection
+	violations []models.AccessibilityViolation
+	counts     map[string]int
+}
+
+// auditAccessibility checks a page for common WCAG failures that can be seen
+// in its markup: a missing <html lang>, form fields without labels, images
+// without alt text, links and buttons without an accessible name, duplicate
+// ids, missing main, banner, navigation or contentinfo landmarks and invalid
+// ARIA roles. It returns the violations, each with a selector path to the
+// offending element, and the number of violations per rule.
+func auditAccessibility(doc *goquery.Document) ([]models.AccessibilityViolation, map[string]int) {
+	a := &accessibilityAuditor{
+		doc:      doc,
+		idCounts: make(map[string]int),
+		ids:      make(map[string]*goquery.Selection),
+		counts:   make(map[string]int),
+	}
+	doc.Find("[id]").Each(func(_ int, element *goquery.Selection) {
+		if id := element.AttrOr("id", ""); id != "" {
+			a.idCounts[id]++
+			if a.ids[id] == nil {
+				a.ids[id] = element
+			}
+		}
+	})
+
+	a.checkHTMLLang()
+	a.checkLabels()
+	a.checkImageAlt()
+	a.checkLinkNames()
+	a.checkButtonNames()
+	a.checkDuplicateIDs()
+	a.checkLandmarks()
+	a.checkARIARoles()
+
+	return a.violations, a.counts
+}
+
+func (a *accessibilityAuditor) report(rule string, element *goquery.Selection, message string) {
+	a.counts[rule]++
+	if a.counts[rule] > maxViolationsPerRule {
+		return
+	}
+	a.violations = append(a.violations, models.AccessibilityViolation{
+		Rule:     rule,
+		Selector: a.selectorPath(element.Get(0)),
+		Message:  message,
+	})
+}
+
+func (a *accessibilityAuditor) checkHTMLLang() {
+	root := a.doc.Find("html").First()
+	if strings.TrimSpace(root.AttrOr("lang", "")) == "" && strings.TrimSpace(root.AttrOr("xml:lang", "")) == "" {
+		a.report(models.AccessibilityHTMLLang, root, "<html> element has no lang attribute")
+	}
+}
+
+func (a *accessibilityAuditor) checkLabels() {
+	labelled := make(map[string]bool)
+	a.doc.Find("label[for]").Each(func(_ int, label *goquery.Selection) {
+		labelled[label.AttrOr("for", "")] = true
+	})
+
+	a.doc.Find("input, select, textarea").Each(func(_ int, field *goquery.Selection) {
+		if goquery.NodeName(field) == "input" && unlabeledInputTypes[inputType(field)] {
+			return
+		}
+		if id := field.AttrOr("id", ""); id != "" && labelled[id] {
+			return
+		}
+		if field.Closest("label").Length() > 0 || a.ariaName(field) != "" || strings.TrimSpace(field.AttrOr("title", "")) != "" {
+			return
+		}
+		a.report(models.AccessibilityLabel, field, fmt.Sprintf("<%s> has no label", goquery.NodeName(field)))
+	})
+}
+
+func (a *accessibilityAuditor) checkImageAlt() {
+	a.doc.Find("img").Each(func(_ int, img *goquery.Selection) {
+		if _, hasAlt := img.Attr("alt"); hasAlt || isHiddenFromAccessibility(img) || a.ariaName(img) != "" {
+			return
+		}
+		a.report(models.AccessibilityImageAlt, img, "<img> has no alt attribute")
+	})
+}
+
+func (a *accessibilityAuditor) checkLinkNames() {
+	a.doc.Find("a[href]").Each(func(_ int, link *goquery.Selection) {
+		if isHiddenFromAccessibility(link) || a.accessibleName(link) != "" {
+			return
+		}
+		a.report(models.AccessibilityLinkName, link, "link has no text or accessible name")
+	})
+}
+
+func (a *accessibilityAuditor) checkButtonNames() {
+	a.doc.Find("button, [role='button'], input").Each(func(_ int, button *goquery.Selection) {
+		if goquery.NodeName(button) == "input" {
+			switch inputType(button) {
+			case "button":
+				if strings.TrimSpace(button.AttrOr("value", "")) != "" || a.ariaName(button) != "" {
+					return
+				}
+			case "image":
+				if strings.TrimSpace(button.AttrOr("alt", "")) != "" || a.ariaName(button) != "" {
+					return
+				}
+			default:
+				// Submit and reset buttons have a default label; other inputs aren't buttons
+				return
+			}
+		} else if isHiddenFromAccessibility(button) || a.accessibleName(button) != "" {
+			return
+		}
+		a.report(models.AccessibilityButtonName, button, "button has no text or accessible name")
+	})
+}
+
+func (a *accessibilityAuditor) checkDuplicateIDs() {
+	seen := make(map[string]bool)
+	a.doc.Find("[id]").Each(func(_ int, element *goquery.Selection) {
+		id := element.AttrOr("id", "")
+		if id == "" {
+			return
+		}
+		if seen[id] {
+			a.report(models.AccessibilityDuplicateID, element, fmt.Sprintf("id %q is used %d times", id, a.idCounts[id]))
+		}
+		seen[id] = true
+	})
+}
+
+func (a *accessibilityAuditor) checkLandmarks() {
+	for _, landmark := range pageLandmarks {
+		found := a.doc.Find(fmt.Sprintf("[role='%s']", landmark.role)).Length() > 0
+		if !found {
+			a.doc.Find(landmark.element).EachWithBreak(func(_ int, element *goquery.Selection) bool {
+				found = !landmark.scoped || element.ParentsFiltered(sectioningElements).Length() == 0
+				return !found
+			})
+		}
+		if !found {
+			a.report(landmark.rule, a.doc.Find("body").First(), landmark.message)
+		}
+	}
+}
+
+func (a *accessibilityAuditor) checkARIARoles() {
+	a.doc.Find("[role]").Each(func(_ int, element *goquery.Selection) {
+		for _, role := range strings.Fields(strings.ToLower(element.AttrOr("role", ""))) {
+			if !ariaRoles[role] {
+				a.report(models.AccessibilityARIARole, element, fmt.Sprintf("role %q is not a valid ARIA role", role))
+				return
+			}
+		}
+	})
+}
+
+// accessibleName approximates the name assistive technology announces for an
+// element: its ARIA label, its text, the alt text of images inside it or its
+// title.
+func (a *accessibilityAuditor) accessibleName(element *goquery.Selection) string {
+	if name := a.ariaName(element); name != "" {
+		return name
+	}
+	if text := strings.TrimSpace(element.Text()); text != "" {
+		return text
+	}
+	var alt string
+	element.Find("img[alt]").EachWithBreak(func(_ int, img *goquery.Selection) bool {
+		alt = strings.TrimSpace(img.AttrOr("alt", ""))
+		return alt == ""
+	})
+	if alt != "" {
+		return alt
+	}
+	return strings.TrimSpace(element.AttrOr("title", ""))
+}
+
+// ariaName returns the element's aria-labelledby text or aria-label.
+func (a *accessibilityAuditor) ariaName(element *goquery.Selection) string {
+	var parts []string
+	for _, id := range strings.Fields(element.AttrOr("aria-labelledby", "")) {
+		if labelledBy := a.ids[id]; labelledBy != nil {
+			parts = append(parts, strings.TrimSpace(labelledBy.Text()))
+		}
+	}
+	if name := strings.TrimSpace(strings.Join(parts, " ")); name != "" {
+		return name
+	}
+	return strings.TrimSpace(element.AttrOr("aria-label", ""))
+}
+
+func isHiddenFromAccessibility(element *goquery.Selection) bool {
+	if element.AttrOr("aria-hidden", "") == "true" {
+		return true
+	}
+	role := strings.ToLower(strings.TrimSpace(element.AttrOr("role", "")))
+	return role == "presentation" || role == "none"
+}
+
+// selectorPath builds a CSS selector that matches node, such as
+// "html > body > form:nth-of-type(2) > input:nth-of-type(3)". It starts from
+// the nearest ancestor with a unique id when there is one.
+func (a *accessibilityAuditor) selectorPath(node *html.Node) string {
+	var parts []string
+	for n := node; n != nil && n.Type == html.ElementNode; n = n.Parent {
+		part := n.Data
+		if id := nodeAttr(n, "id"); a.idCounts[id] == 1 && cssIdentifier.MatchString(id) {
+			parts = append(parts, part+"#"+id)
+			break
+		}
+		if index, total := nthOfType(n); total > 1 {
+			part += fmt.Sprintf(":nth-of-type(%d)", index)
+		}
+		parts = append(parts, part)
+	}
+
+	for i, j := 0, len(parts)-1; i < j; i, j = i+1, j-1 {
+		parts[i], parts[j] = parts[j], parts[i]
+	}
+	return strings.Join(parts, " > ")
+}
+
+// nthOfType returns the 1-based position of n among its siblings with the
+// same tag, and how many such siblings there are.
+func nthOfType(n *html.Node) (index, total int) {
+	if n.Parent == nil {
+		return 1, 1
+	}
+	for sibling := n.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+		if sibling.Type != html.ElementNode || sibling.Data != n.Data {
+			continue
+		}
+		total++
+		if sibling == n {
+			index = total
+		}
+	}
+	return index, total
+}
+
+func nodeAttr(n *html.Node, name string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+)
+
+func TestAuditAccessibility(t *testing.T) {
+	html := `<html><body>
+<div id="nav" role="navigation">
+	<a href="/"><img src="/logo.png"></a>
+	<a href="/about">About</a>
+	<a href="/help" aria-label="Help"><svg></svg></a>
+</div>
+<form id="search">
+	<input type="text" name="q">
+	<label for="email">Email</label><input id="email" type="email">
+	<label>Name <input name="name"></label>
+	<input type="hidden" name="token">
+	<input type="submit">
+	<button type="button"><span class="icon"></span></button>
+	<button>Go</button>
+</form>
+<div id="dup"></div><p id="dup"></p>
+<div role="banana"></div>
+<div role="presentation"></div>
+<img src="/decorative.png" alt="">
+</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	violations, counts := auditAccessibility(doc)
+
+	expectedCounts := map[string]int{
+		models.AccessibilityHTMLLang:    1,
+		models.AccessibilityLabel:       1,
+		models.AccessibilityImageAlt:    1,
+		models.AccessibilityLinkName:    1,
+		models.AccessibilityButtonName:  1,
+		models.AccessibilityDuplicateID: 1,
+		models.AccessibilityLandmark:    1,
+		models.AccessibilityBanner:      1,
+		models.AccessibilityContentInfo: 1,
+		models.AccessibilityARIARole:    1,
+	}
+	for rule, expected := range expectedCounts {
+		if counts[rule] != expected {
+			t.Errorf("Expected %d %s violations, got %d", expected, rule, counts[rule])
+		}
+	}
+	if len(counts) != len(expectedCounts) {
+		t.Errorf("Unexpected rules reported: %v", counts)
+	}
+
+	selectors := make(map[string]string)
+	for _, violation := range violations {
+		selectors[violation.Rule] = violation.Selector
+	}
+	expectedSelectors := map[string]string{
+		models.AccessibilityHTMLLang:    "html",
+		models.AccessibilityLabel:       "form#search > input:nth-of-type(1)",
+		models.AccessibilityImageAlt:    "div#nav > a:nth-of-type(1) > img",
+		models.AccessibilityLinkName:    "div#nav > a:nth-of-type(1)",
+		models.AccessibilityButtonName:  "form#search > button:nth-of-type(1)",
+		models.AccessibilityDuplicateID: "html > body > p",
+		models.AccessibilityLandmark:    "html > body",
+		models.AccessibilityBanner:      "html > body",
+		models.AccessibilityContentInfo: "html > body",
+		models.AccessibilityARIARole:    "html > body > div:nth-of-type(3)",
+	}
+	for rule, expected := range expectedSelectors {
+		if selectors[rule] != expected {
+			t.Errorf("Expected %s selector '%s', got '%s'", rule, expected, selectors[rule])
+		}
+	}
+}
+
+func TestAuditAccessibility_AccessiblePage(t *testing.T) {
+	html := `<html lang="en"><body>
+<header><nav><a href="/">Home</a></nav></header>
+<main>
+	<h1>Title</h1>
+	<img src="/a.png" alt="A chart">
+	<span id="q-label">Search</span><input aria-labelledby="q-label">
+	<button aria-label="Close">×</button>
+</main>
+<footer>© Example</footer>
+</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	violations, counts := auditAccessibility(doc)
+
+	if len(violations) != 0 || len(counts) != 0 {
+		t.Errorf("Expected no violations, got %+v", violations)
+	}
+}
+
+func TestAuditAccessibility_SectionHeaderIsNotBanner(t *testing.T) {
+	html := `<html lang="en"><body>
+<nav><a href="/">Home</a></nav>
+<main><article><header>Article title</header><p>Text</p><footer>Article footer</footer></article></main>
+</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	_, counts := auditAccessibility(doc)
+
+	if counts[models.AccessibilityBanner] != 1 || counts[models.AccessibilityContentInfo] != 1 || len(counts) != 2 {
+		t.Errorf("Expected only missing banner and contentinfo landmarks, got %v", counts)
+	}
+}
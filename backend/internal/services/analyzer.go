@@ -0,0 +1,171 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+)
+
+// AnalyzerInput is what an analyzer is given for each crawled page.
+// Response has already been read, so only its status and headers are
+// available; the page is in Document. Result holds the crawler's analysis of
+// the page. The crawler's own analyzers fill their fields of it; any other
+// analyzer must not modify it and reports through its findings instead.
+type AnalyzerInput struct {
+	URL      *models.URL
+	PageURL  string
+	Response *http.Response
+	Document *goquery.Document
+	Result   *models.CrawlResult
+
+	// crawler and robots let the crawler's own analyzers check URLs with
+	// the page's crawl profile and robots.txt policy
+	crawler *crawlerService
+	robots  robotsPolicy
+}
+
+// Analyzer is a check run on every crawled HTML page. Its findings are stored
+// with the crawl result, so adding one needs no changes to the crawler or the
+// database schema. Analyzers are registered with RegisterAnalyzer, usually
+// from an init function in the file that defines them.
+type Analyzer interface {
+	// Name identifies the analyzer in findings, in the DISABLED_ANALYZERS
+	// setting and in per-URL overrides. It must be unique.
+	Name() string
+	Description() string
+	Analyze(input *AnalyzerInput) ([]models.Finding, error)
+}
+
+// AnalyzerInfo describes a registered analyzer.
+type AnalyzerInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// AnalyzerRegistry holds the analyzers the crawler runs.
+type AnalyzerRegistry struct {
+	analyzers map[string]Analyzer
+}
+
+func NewAnalyzerRegistry() *AnalyzerRegistry {
+	return &AnalyzerRegistry{analyzers: make(map[string]Analyzer)}
+}
+
+// defaultAnalyzers is the registry the crawler uses.
+var defaultAnalyzers = NewAnalyzerRegistry()
+
+// RegisterAnalyzer adds an analyzer to the crawler. It panics if the name is
+// already taken, since that is a programming error.
+func RegisterAnalyzer(analyzer Analyzer) {
+	if err := defaultAnalyzers.Register(analyzer); err != nil {
+		panic(err)
+	}
+}
+
+// LookupAnalyzer reports whether an analyzer with the given name is
+// registered with the crawler.
+func LookupAnalyzer(name string) bool {
+	_, ok := defaultAnalyzers.analyzers[name]
+	return ok
+}
+
+// ListAnalyzers describes the crawler's analyzers, marking the ones disabled
+// by configuration.
+func ListAnalyzers(disabled []string) []AnalyzerInfo {
+	return defaultAnalyzers.List(disabled)
+}
+
+func (r *AnalyzerRegistry) Register(analyzer Analyzer) error {
+	name := analyzer.Name()
+	if name == "" {
+		return fmt.Errorf("analyzer has no name")
+	}
+	if _, exists := r.analyzers[name]; exists {
+		return fmt.Errorf("analyzer %q is already registered", name)
+	}
+	r.analyzers[name] = analyzer
+	return nil
+}
+
+// List describes every analyzer in name order.
+func (r *AnalyzerRegistry) List(disabled []string) []AnalyzerInfo {
+	off := toSet(disabled...)
+	infos := make([]AnalyzerInfo, 0, len(r.analyzers))
+	for _, analyzer := range r.sorted() {
+		infos = append(infos, AnalyzerInfo{
+			Name:        analyzer.Name(),
+			Description: analyzer.Description(),
+			Enabled:     !off[analyzer.Name()],
+		})
+	}
+	return infos
+}
+
+// enabledFor returns the analyzers to run for a URL in name order. A URL's
+// own setting for an analyzer wins over the configuration, so an analyzer
+// disabled by default can still be enabled for a single URL.
+func (r *AnalyzerRegistry) enabledFor(url *models.URL, disabled []string) []Analyzer {
+	off := toSet(disabled...)
+	var enabled []Analyzer
+	for _, analyzer := range r.sorted() {
+		on, overridden := url.Analyzers[analyzer.Name()]
+		if !overridden {
+			on = !off[analyzer.Name()]
+		}
+		if on {
+			enabled = append(enabled, analyzer)
+		}
+	}
+	return enabled
+}
+
+func (r *AnalyzerRegistry) sorted() []Analyzer {
+	analyzers := make([]Analyzer, 0, len(r.analyzers))
+	for _, analyzer := range r.analyzers {
+		analyzers = append(analyzers, analyzer)
+	}
+	sort.Slice(analyzers, func(i, j int) bool { return analyzers[i].Name() < analyzers[j].Name() })
+	return analyzers
+}
+
+// runAnalyzers runs the analyzers enabled for the page's URL and collects
+// their findings. An analyzer that fails or panics is logged and skipped, so
+// one faulty check cannot fail the crawl.
+func (s *crawlerService) runAnalyzers(input *AnalyzerInput) []models.Finding {
+	registry := s.analyzers
+	if registry == nil {
+		registry = defaultAnalyzers
+	}
+	var disabled []string
+	if s.config != nil {
+		disabled = s.config.DisabledAnalyzers
+	}
+
+	var findings []models.Finding
+	for _, analyzer := range registry.enabledFor(input.URL, disabled) {
+		found, err := runAnalyzer(analyzer, input)
+		if err != nil {
+			log.Printf("Analyzer %s failed on %s: %v", analyzer.Name(), input.PageURL, err)
+			continue
+		}
+		for _, finding := range found {
+			finding.Analyzer = analyzer.Name()
+			findings = append(findings, finding)
+		}
+	}
+	return findings
+}
+
+func runAnalyzer(analyzer Analyzer, input *AnalyzerInput) (findings []models.Finding, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			findings, err = nil, fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return analyzer.Analyze(input)
+}
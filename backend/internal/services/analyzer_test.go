@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+	"sykell-crawler/pkg/config"
+)
+
+type stubAnalyzer struct {
+	name     string
+	findings []models.Finding
+	err      error
+	panics   bool
+}
+
+func (a stubAnalyzer) Name() string        { return a.name }
+func (a stubAnalyzer) Description() string { return "stub" }
+
+func (a stubAnalyzer) Analyze(input *AnalyzerInput) ([]models.Finding, error) {
+	if a.panics {
+		panic("boom")
+	}
+	return a.findings, a.err
+}
+
+func TestAnalyzerRegistry_EnabledFor(t *testing.T) {
+	registry := NewAnalyzerRegistry()
+	for _, name := range []string{"beta", "alpha", "gamma"} {
+		if err := registry.Register(stubAnalyzer{name: name}); err != nil {
+			t.Fatalf("Expected no error registering %s, got %v", name, err)
+		}
+	}
+	if err := registry.Register(stubAnalyzer{name: "alpha"}); err == nil {
+		t.Error("Expected an error registering a duplicate name")
+	}
+
+	names := func(analyzers []Analyzer) string {
+		var names []string
+		for _, analyzer := range analyzers {
+			names = append(names, analyzer.Name())
+		}
+		return strings.Join(names, ",")
+	}
+
+	if got := names(registry.enabledFor(&models.URL{}, nil)); got != "alpha,beta,gamma" {
+		t.Errorf("Expected every analyzer in name order, got %s", got)
+	}
+	if got := names(registry.enabledFor(&models.URL{}, []string{"beta"})); got != "alpha,gamma" {
+		t.Errorf("Expected beta to be disabled by configuration, got %s", got)
+	}
+
+	url := &models.URL{Analyzers: map[string]bool{"beta": true, "gamma": false}}
+	if got := names(registry.enabledFor(url, []string{"beta"})); got != "alpha,beta" {
+		t.Errorf("Expected the URL's overrides to win over configuration, got %s", got)
+	}
+}
+
+func TestRunAnalyzers(t *testing.T) {
+	service := NewCrawlerService(nil, nil, nil, nil, &config.Config{DisabledAnalyzers: []string{"off"}}).(*crawlerService)
+	service.analyzers = NewAnalyzerRegistry()
+	service.analyzers.Register(stubAnalyzer{name: "good", findings: []models.Finding{{Type: "thing", Severity: models.FindingInfo}}})
+	service.analyzers.Register(stubAnalyzer{name: "failing", err: errors.New("failed"), findings: []models.Finding{{Type: "partial"}}})
+	service.analyzers.Register(stubAnalyzer{name: "panicking", panics: true})
+	service.analyzers.Register(stubAnalyzer{name: "off", findings: []models.Finding{{Type: "disabled"}}})
+
+	findings := service.runAnalyzers(&AnalyzerInput{URL: &models.URL{}, PageURL: "https://example.com/"})
+	if len(findings) != 1 || findings[0].Analyzer != "good" || findings[0].Type != "thing" {
+		t.Errorf("Expected only the working analyzer's finding, got %+v", findings)
+	}
+}
+
+func TestCrawlURL_BuiltInAnalyzersCanBeDisabled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>Sign in</title>
+<script type="application/ld+json">{"@context": "https://schema.org", "@type": "Organization", "name": "Example"}</script>
+</head><body>
+<img src="/logo.png">
+<form action="/login" method="post"><input type="text" name="username"><input type="password" name="password"></form>
+</body></html>`))
+	}))
+	defer server.Close()
+
+	for _, name := range []string{"accessibility", "extraction-rules", "forms", "images", "mixed-content", "resources", "structured-data"} {
+		if !LookupAnalyzer(name) {
+			t.Errorf("Expected the %s analyzer to be registered", name)
+		}
+	}
+
+	cfg := createTestConfig()
+	cfg.DisabledAnalyzers = []string{"accessibility", "images", "structured-data"}
+	urlRepo := &mockURLRepository{urls: map[uint]*models.URL{
+		1: {ID: 1, URL: server.URL, Status: models.StatusQueued, Analyzers: map[string]bool{"accessibility": true}},
+	}}
+	resultRepo := &mockCrawlResultRepository{results: make(map[uint]*models.CrawlResult)}
+	service := NewCrawlerService(urlRepo, resultRepo, nil, &mockQueueService{}, cfg)
+	if err := service.CrawlURL(context.Background(), 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	result := resultRepo.results[1]
+	if len(result.StructuredData) != 0 || result.Images != 0 {
+		t.Errorf("Expected disabled analyzers not to run, got %d structured data items and %d images", len(result.StructuredData), result.Images)
+	}
+	if result.AccessibilityViolationCount == 0 {
+		t.Error("Expected the URL's setting to enable the accessibility analyzer")
+	}
+	if !result.HasLoginForm {
+		t.Error("Expected the forms analyzer to run")
+	}
+}
+
+func TestMetaRefreshAnalyzer(t *testing.T) {
+	html := `<html><head>
+<meta http-equiv="Refresh" content="5; URL='/new-page'">
+<meta http-equiv="refresh" content="300">
+<meta http-equiv="content-type" content="text/html">
+</head><body></body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	findings, err := metaRefreshAnalyzer{}.Analyze(&AnalyzerInput{PageURL: "https://example.com/old", Document: doc})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("Expected 2 findings, got %+v", findings)
+	}
+	if findings[0].Type != "redirect" || findings[0].Data["url"] != "https://example.com/new-page" || findings[0].Data["delay"] != 5 {
+		t.Errorf("Expected a redirect to /new-page after 5 seconds, got %+v", findings[0])
+	}
+	if findings[1].Type != "reload" || findings[1].Severity != models.FindingInfo {
+		t.Errorf("Expected a reload finding, got %+v", findings[1])
+	}
+}
+
+func TestParseMetaRefresh(t *testing.T) {
+	tests := []struct {
+		content string
+		delay   int
+		url     string
+	}{
+		{"0;url=https://example.com/", 0, "https://example.com/"},
+		{"3.5, URL = \"/next\"", 3, "/next"},
+		{"10", 10, ""},
+		{"url=/only-url", 0, "/only-url"},
+	}
+	for _, test := range tests {
+		delay, url := parseMetaRefresh(test.content)
+		if delay != test.delay || url != test.url {
+			t.Errorf("parseMetaRefresh(%q) = %d, %q; expected %d, %q", test.content, delay, url, test.delay, test.url)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html/charset"
+)
+
+// contentSniffSize is how much of the body is looked at to sniff the content
+// type and detect the charset.
+const contentSniffSize = 64 * 1024
+
+// htmlMIMETypes are the content types the crawler can analyze.
+var htmlMIMETypes = map[string]bool{
+	"text/html":             true,
+	"application/xhtml+xml": true,
+}
+
+// pageContent is a response body prepared for parsing. Body yields UTF-8 for
+// HTML pages and is nil otherwise.
+type pageContent struct {
+	MIMEType string
+	Charset  string
+	HTML     bool
+	Body     io.Reader
+}
+
+// decodePageContent determines the MIME type of a response, sniffing the body
+// when the server does not declare one, and wraps HTML bodies in a decoder
+// that transcodes them to UTF-8. The charset comes from a byte order mark,
+// the Content-Type header or a <meta> declaration, in that order.
+func decodePageContent(resp *http.Response) (*pageContent, error) {
+	reader := bufio.NewReaderSize(resp.Body, contentSniffSize)
+	head, err := reader.Peek(contentSniffSize)
+	if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+		return nil, err
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || mediaType == "application/octet-stream" {
+		// A sniffed type always claims charset=utf-8, which would skip the
+		// <meta> prescan, so only its media type is used
+		mediaType, _, _ = mime.ParseMediaType(http.DetectContentType(head))
+		contentType = mediaType
+	}
+	mediaType = strings.ToLower(mediaType)
+
+	content := &pageContent{MIMEType: mediaType}
+	if !htmlMIMETypes[mediaType] {
+		return content, nil
+	}
+	content.HTML = true
+
+	encoding, name, certain := charset.DetermineEncoding(head, contentType)
+	if !certain && name == "windows-1252" && validUTF8Prefix(head) {
+		// Undeclared pages are far more often UTF-8 than windows-1252, which
+		// DetermineEncoding falls back to when the first 1KB is plain ASCII
+		encoding, name = nil, "utf-8"
+	}
+	content.Charset = name
+
+	if encoding == nil {
+		content.Body = reader
+	} else {
+		content.Body = encoding.NewDecoder().Reader(reader)
+	}
+	return content, nil
+}
+
+// validUTF8Prefix reports whether b is valid UTF-8, ignoring a rune that may
+// have been cut off at the end.
+func validUTF8Prefix(b []byte) bool {
+	for i := len(b) - 1; i >= 0 && i >= len(b)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(b[i]) {
+			if !utf8.FullRune(b[i:]) {
+				b = b[:i]
+			}
+			break
+		}
+	}
+	return utf8.Valid(b)
+}
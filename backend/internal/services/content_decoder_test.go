@@ -0,0 +1,135 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sykell-crawler/internal/models"
+)
+
+func TestDecodePageContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        []byte
+		mimeType    string
+		charset     string
+		text        string
+	}{
+		{
+			name:        "Shift_JIS from header",
+			contentType: "text/html; charset=Shift_JIS",
+			body:        append([]byte("<html><body>"), 0x93, 0xfa, 0x96, 0x7b, 0x8c, 0xea),
+			mimeType:    "text/html",
+			charset:     "shift_jis",
+			text:        "<html><body>日本語",
+		},
+		{
+			name:        "windows-1251 from meta",
+			contentType: "text/html",
+			body:        append([]byte(`<html><head><meta charset="windows-1251"></head><body>`), 0xcf, 0xf0, 0xe8, 0xe2, 0xe5, 0xf2),
+			mimeType:    "text/html",
+			charset:     "windows-1251",
+			text:        `<html><head><meta charset="windows-1251"></head><body>Привет`,
+		},
+		{
+			name:     "Shift_JIS from meta without a header",
+			body:     append([]byte(`<html><head><meta charset="shift_jis"></head><body>`), 0x93, 0xfa, 0x96, 0x7b, 0x8c, 0xea),
+			mimeType: "text/html",
+			charset:  "shift_jis",
+			text:     `<html><head><meta charset="shift_jis"></head><body>日本語`,
+		},
+		{
+			name:     "Undeclared UTF-8",
+			body:     []byte("<!DOCTYPE html><html><body>Grüße</body></html>"),
+			mimeType: "text/html",
+			charset:  "utf-8",
+			text:     "<!DOCTYPE html><html><body>Grüße</body></html>",
+		},
+		{
+			name:        "PDF",
+			contentType: "application/pdf",
+			body:        []byte("%PDF-1.7"),
+			mimeType:    "application/pdf",
+		},
+		{
+			name:        "Image labelled as octet-stream",
+			contentType: "application/octet-stream",
+			body:        []byte("\x89PNG\r\n\x1a\n"),
+			mimeType:    "image/png",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := &http.Response{
+				Header: http.Header{},
+				Body:   io.NopCloser(bytes.NewReader(tt.body)),
+			}
+			if tt.contentType != "" {
+				resp.Header.Set("Content-Type", tt.contentType)
+			}
+
+			content, err := decodePageContent(resp)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			if content.MIMEType != tt.mimeType {
+				t.Errorf("Expected MIME type '%s', got '%s'", tt.mimeType, content.MIMEType)
+			}
+			if content.HTML != (tt.text != "") {
+				t.Fatalf("Expected HTML to be %v", tt.text != "")
+			}
+			if !content.HTML {
+				return
+			}
+			if content.Charset != tt.charset {
+				t.Errorf("Expected charset '%s', got '%s'", tt.charset, content.Charset)
+			}
+			text, err := io.ReadAll(content.Body)
+			if err != nil {
+				t.Fatalf("Failed to read body: %v", err)
+			}
+			if string(text) != tt.text {
+				t.Errorf("Expected '%s', got '%s'", tt.text, text)
+			}
+		})
+	}
+}
+
+func TestCrawlURL_MarksNonHTMLAsUnsupported(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Write([]byte("%PDF-1.7"))
+	}))
+	defer server.Close()
+
+	urlRepo := &mockURLRepository{
+		urls: map[uint]*models.URL{
+			1: {ID: 1, URL: server.URL, Status: models.StatusQueued},
+		},
+	}
+	resultRepo := &mockCrawlResultRepository{
+		results: make(map[uint]*models.CrawlResult),
+	}
+
+	service := NewCrawlerService(urlRepo, resultRepo, nil, &mockQueueService{}, createTestConfig())
+	if err := service.CrawlURL(context.Background(), 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if status := urlRepo.urls[1].Status; status != models.StatusUnsupported {
+		t.Errorf("Expected status '%s', got '%s'", models.StatusUnsupported, status)
+	}
+	result := resultRepo.results[1]
+	if result == nil {
+		t.Fatal("Expected crawl result to be saved")
+	}
+	if !result.UnsupportedContent || result.ContentType != "application/pdf" {
+		t.Errorf("Expected unsupported application/pdf content, got %v and '%s'", result.UnsupportedContent, result.ContentType)
+	}
+}
@@ -0,0 +1,69 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+const (
+	// minFingerprintWords is the fewest words a content fingerprint is computed
+	// from; shorter pages are too alike for near-duplicate matching to mean much.
+	minFingerprintWords = 20
+	// shingleSize is the number of consecutive words hashed together, so the
+	// fingerprint reflects word order and not only vocabulary.
+	shingleSize = 3
+)
+
+// contentSimHash returns the 64-bit SimHash of the word shingles of a text as
+// 16 hex digits, or an empty string when there are too few words. Texts that
+// differ in a few words have fingerprints that differ in a few bits.
+func contentSimHash(words []string) string {
+	if len(words) < minFingerprintWords {
+		return ""
+	}
+
+	var weights [64]int
+	for i := 0; i+shingleSize <= len(words); i++ {
+		hash := fnv.New64a()
+		hash.Write([]byte(strings.Join(words[i:i+shingleSize], " ")))
+		sum := hash.Sum64()
+		for bit := 0; bit < 64; bit++ {
+			if sum&(1<<bit) != 0 {
+				weights[bit]++
+			} else {
+				weights[bit]--
+			}
+		}
+	}
+
+	var fingerprint uint64
+	for bit, weight := range weights {
+		if weight > 0 {
+			fingerprint |= 1 << bit
+		}
+	}
+	return fmt.Sprintf("%016x", fingerprint)
+}
+
+// parseSimHash decodes a hex fingerprint, reporting false for an empty or
+// malformed one.
+func parseSimHash(hash string) (uint64, bool) {
+	value, err := strconv.ParseUint(hash, 16, 64)
+	return value, err == nil
+}
+
+// textHash returns the SHA-256 of a text with case and whitespace
+// normalized, or an empty string for empty text, so missing titles are not
+// reported as duplicates of each other.
+func textHash(text string) string {
+	normalized := strings.ToLower(strings.Join(strings.Fields(text), " "))
+	if normalized == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,320 @@
+package services
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+)
+
+const (
+	// minLanguageWords is the fewest words a language is detected from.
+	minLanguageWords = 20
+	// minReadabilityWords is the fewest words a readability score is given for.
+	minReadabilityWords = 30
+)
+
+// hiddenContentTags are elements whose text is not part of a page's content.
+var hiddenContentTags = toSet("head", "script", "style", "noscript", "template", "nav", "svg", "iframe", "object")
+
+// blockTags are elements that start a new line of text, so their text is not
+// run together with the text around them.
+var blockTags = toSet(
+	"address", "article", "aside", "blockquote", "br", "dd", "details", "div", "dl", "dt", "fieldset",
+	"figcaption", "figure", "footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hr", "li",
+	"main", "ol", "p", "pre", "section", "summary", "table", "td", "th", "tr", "ul",
+)
+
+// stopwords are frequent short words used to tell Latin-script languages
+// apart.
+var stopwords = map[string]map[string]bool{
+	"en": toSet("the", "and", "of", "to", "in", "is", "that", "for", "it", "with", "as", "was", "on", "are", "be",
+		"this", "by", "you", "not", "or", "have", "from", "at", "which", "but", "an", "they", "we", "can", "will"),
+	"de": toSet("der", "die", "und", "den", "von", "zu", "das", "mit", "sich", "des", "auf", "für", "ist", "im",
+		"dem", "nicht", "ein", "eine", "als", "auch", "es", "werden", "aus", "er", "hat", "dass", "sie", "nach", "bei", "wir", "oder", "ich"),
+	"fr": toSet("le", "la", "les", "de", "des", "et", "un", "une", "du", "est", "que", "qui", "dans", "pour", "pas",
+		"sur", "au", "par", "avec", "ce", "il", "elle", "sont", "ne", "se", "plus", "nous", "vous", "mais"),
+	"es": toSet("el", "los", "las", "de", "y", "en", "que", "del", "un", "una", "es", "por", "con", "para", "se",
+		"no", "al", "lo", "como", "más", "pero", "sus", "su", "ya", "este", "porque", "está", "son", "muy"),
+	"it": toSet("il", "lo", "gli", "di", "e", "che", "un", "una", "del", "della", "per", "non", "con", "sono", "è",
+		"da", "al", "come", "anche", "più", "ma", "si", "nel", "questo", "ci", "ha", "dei", "alla"),
+	"pt": toSet("o", "os", "de", "e", "que", "do", "da", "em", "um", "uma", "para", "com", "não", "por", "se",
+		"dos", "das", "no", "na", "mais", "como", "mas", "ao", "ele", "ela", "são", "foi", "está", "você"),
+	"nl": toSet("de", "het", "een", "en", "van", "is", "dat", "op", "te", "zijn", "voor", "met", "die", "niet",
+		"aan", "er", "om", "ook", "als", "bij", "maar", "door", "worden", "wordt", "naar", "heeft", "je", "wij", "ze"),
+	"pl": toSet("i", "w", "na", "z", "się", "nie", "do", "to", "jest", "że", "o", "jak", "po", "co", "ale", "od",
+		"za", "dla", "czy", "jego", "są", "tak", "przez", "już", "tylko", "może", "oraz", "być"),
+}
+
+// stopwordLanguages fixes the order languages are compared in, so ties are
+// resolved the same way every time.
+var stopwordLanguages = []string{"en", "de", "fr", "es", "it", "pt", "nl", "pl"}
+
+// scriptLanguages are the languages detectLanguage tells from their writing
+// system alone.
+var scriptLanguages = toSet("ja", "zh", "ko", "ru", "uk", "ar", "el", "he", "th", "hi")
+
+// readabilityFormulas are the Flesch reading ease formula and its adaptations
+// to other languages: base - asl*(words per sentence) - asw*(syllables per word).
+var readabilityFormulas = map[string]struct{ base, asl, asw float64 }{
+	"en": {206.835, 1.015, 84.6}, // Flesch
+	"de": {180, 1, 58.5},         // Amstad
+	"fr": {207, 1.015, 73.6},     // Kandel and Moles
+	"es": {206.84, 1.02, 60},     // Fernández Huerta
+	"it": {217, 1.3, 60},         // Flesch-Vacca
+	"pt": {248.835, 1.015, 84.6}, // Martins
+	"nl": {206.835, 0.93, 77},    // Flesch-Douma
+}
+
+// contentStats describes the visible text of a page.
+type contentStats struct {
+	Text      string
+	WordCount int
+	// TextRatio is the visible text's share of the HTML in percent
+	TextRatio float64
+	// Language is an ISO 639-1 code, or empty when it could not be detected
+	Language string
+	// Readability is a Flesch reading ease score from 0 (hard) to 100
+	// (easy), or nil when the language has no formula or the text is short
+	Readability *float64
+	// SimHash is the fingerprint near-duplicate pages are found by
+	SimHash string
+}
+
+// analyzeContent extracts the text a visitor reads on the page, leaving out
+// scripts, styles and navigation, and measures it. htmlBytes is the size of
+// the page's HTML.
+func analyzeContent(doc *goquery.Document, htmlBytes int64) contentStats {
+	stats := contentStats{Text: visibleText(doc)}
+
+	words := textWords(stats.Text)
+	stats.WordCount = len(words)
+	if htmlBytes > 0 {
+		stats.TextRatio = math.Round(float64(len(stats.Text))/float64(htmlBytes)*10000) / 100
+	}
+	stats.Language = detectLanguage(stats.Text, words)
+	stats.Readability = readability(stats.Text, words, stats.Language)
+	stats.SimHash = contentSimHash(words)
+	return stats
+}
+
+// primaryLanguage returns the lower-case primary subtag of a language tag,
+// e.g. "pt" for "pt-BR".
+func primaryLanguage(tag string) string {
+	primary, _, _ := strings.Cut(strings.TrimSpace(tag), "-")
+	primary, _, _ = strings.Cut(primary, "_")
+	return strings.ToLower(primary)
+}
+
+// visibleText returns the body text with whitespace collapsed and one line
+// per block of text.
+func visibleText(doc *goquery.Document) string {
+	var builder strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			builder.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if hiddenContentTags[n.Data] {
+				return
+			}
+			if hasNodeAttr(n, "hidden") {
+				return
+			}
+			if blockTags[n.Data] {
+				builder.WriteString("\n")
+				defer builder.WriteString("\n")
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	for _, node := range doc.Find("body").Nodes {
+		walk(node)
+	}
+
+	var lines []string
+	for _, line := range strings.Split(builder.String(), "\n") {
+		if line = strings.Join(strings.Fields(line), " "); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// isIdeograph reports whether r is written without spaces between words, in
+// which case every character is counted as a word.
+func isIdeograph(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Thai)
+}
+
+// textWords splits text into lower-case words.
+func textWords(text string) []string {
+	var words []string
+	var current []rune
+	flush := func() {
+		if len(current) > 0 {
+			words = append(words, strings.ToLower(string(current)))
+			current = current[:0]
+		}
+	}
+	for _, r := range text {
+		switch {
+		case isIdeograph(r):
+			flush()
+			words = append(words, string(r))
+		case unicode.IsLetter(r) || unicode.IsNumber(r) || unicode.Is(unicode.Mn, r) || (r == '\'' && len(current) > 0):
+			current = append(current, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return words
+}
+
+// detectLanguage guesses the language of text from its script, and for
+// Latin script from the stopwords it uses.
+func detectLanguage(text string, words []string) string {
+	if len(words) < minLanguageWords {
+		return ""
+	}
+
+	scripts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, script := range []string{"Han", "Hiragana", "Katakana", "Hangul", "Cyrillic", "Arabic", "Greek", "Hebrew", "Thai", "Devanagari", "Latin"} {
+			if unicode.Is(unicode.Scripts[script], r) {
+				scripts[script]++
+				break
+			}
+		}
+	}
+
+	if scripts["Latin"]*2 < letters {
+		kana := scripts["Hiragana"] + scripts["Katakana"]
+		switch {
+		case kana > 0 && kana*10 >= kana+scripts["Han"]:
+			return "ja"
+		case scripts["Han"] > 0 && scripts["Han"] >= scripts["Hangul"]:
+			return "zh"
+		case scripts["Hangul"] > 0:
+			return "ko"
+		case scripts["Cyrillic"] > 0:
+			if strings.ContainsAny(strings.ToLower(text), "ієїґ") {
+				return "uk"
+			}
+			return "ru"
+		}
+		for _, script := range []struct{ name, language string }{
+			{"Arabic", "ar"}, {"Greek", "el"}, {"Hebrew", "he"}, {"Thai", "th"}, {"Devanagari", "hi"},
+		} {
+			if scripts[script.name]*2 >= letters {
+				return script.language
+			}
+		}
+		return ""
+	}
+
+	best, bestHits := "", 0
+	for _, language := range stopwordLanguages {
+		hits := 0
+		for _, word := range words {
+			if stopwords[language][word] {
+				hits++
+			}
+		}
+		if hits > bestHits {
+			best, bestHits = language, hits
+		}
+	}
+	// Real prose is at least a tenth stopwords; lists of names and prices aren't
+	if bestHits*10 < len(words) {
+		return ""
+	}
+	return best
+}
+
+// detectableLanguage reports whether detectLanguage can return language, so
+// a page declaring any other language is not flagged for a mismatch.
+func detectableLanguage(language string) bool {
+	_, ok := stopwords[language]
+	return ok || scriptLanguages[language]
+}
+
+// readability scores text with the reading ease formula for its language.
+func readability(text string, words []string, language string) *float64 {
+	formula, ok := readabilityFormulas[language]
+	if !ok || len(words) < minReadabilityWords {
+		return nil
+	}
+
+	syllables := 0
+	for _, word := range words {
+		syllables += countSyllables(word, language)
+	}
+	wordsPerSentence := float64(len(words)) / float64(countSentences(text))
+	syllablesPerWord := float64(syllables) / float64(len(words))
+
+	score := formula.base - formula.asl*wordsPerSentence - formula.asw*syllablesPerWord
+	score = math.Round(math.Max(0, math.Min(100, score))*10) / 10
+	return &score
+}
+
+// countSentences counts the sentences in text. Every line is at least one
+// sentence, so headings and list items without punctuation are counted too.
+func countSentences(text string) int {
+	sentences := 0
+	for _, line := range strings.Split(text, "\n") {
+		for _, sentence := range strings.FieldsFunc(line, func(r rune) bool {
+			return strings.ContainsRune(".!?…。！？", r)
+		}) {
+			if strings.IndexFunc(sentence, unicode.IsLetter) >= 0 {
+				sentences++
+			}
+		}
+	}
+	if sentences == 0 {
+		return 1
+	}
+	return sentences
+}
+
+// countSyllables estimates the syllables of a word as its groups of vowels.
+func countSyllables(word, language string) int {
+	count := 0
+	previousVowel := false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouyàáâãäåæèéêëìíîïòóôõöøùúûüýÿœ", r)
+		if vowel && !previousVowel {
+			count++
+		}
+		previousVowel = vowel
+	}
+	// A final silent e, as in "make", is not a syllable in English
+	if language == "en" && count > 1 && strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") {
+		count--
+	}
+	if count == 0 {
+		return 1
+	}
+	return count
+}
+
+func hasNodeAttr(n *html.Node, name string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == name {
+			return true
+		}
+	}
+	return false
+}
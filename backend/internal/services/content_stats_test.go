@@ -0,0 +1,96 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestAnalyzeContent(t *testing.T) {
+	html := `<html lang="en"><head><title>Ignored</title><style>body { color: red }</style></head><body>
+<nav><a href="/">Home</a> <a href="/about">About</a></nav>
+<script>var ignored = "script text";</script>
+<h1>Our cat</h1>
+<p>The cat sat on the mat. It was a warm day and the cat was happy to rest in the sun.</p>
+<p>We gave it some milk, and then it went to sleep for the rest of the afternoon.</p>
+<p hidden>This paragraph is hidden.</p>
+<ul><li>Milk</li><li>Fish</li></ul>
+</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	stats := analyzeContent(doc, int64(len(html)))
+
+	expectedText := "Our cat\nThe cat sat on the mat. It was a warm day and the cat was happy to rest in the sun.\n" +
+		"We gave it some milk, and then it went to sleep for the rest of the afternoon.\nMilk\nFish"
+	if stats.Text != expectedText {
+		t.Errorf("Expected text %q, got %q", expectedText, stats.Text)
+	}
+	if stats.WordCount != 42 {
+		t.Errorf("Expected 42 words, got %d", stats.WordCount)
+	}
+	expectedRatio := float64(len(expectedText)) / float64(len(html)) * 100
+	if stats.TextRatio < expectedRatio-0.01 || stats.TextRatio > expectedRatio+0.01 {
+		t.Errorf("Expected text ratio %.2f, got %.2f", expectedRatio, stats.TextRatio)
+	}
+	if stats.Language != "en" {
+		t.Errorf("Expected language 'en', got '%s'", stats.Language)
+	}
+	if stats.Readability == nil || *stats.Readability < 80 {
+		t.Errorf("Expected simple text to score as easy to read, got %v", stats.Readability)
+	}
+}
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"Der Hund und die Katze sind im Garten, weil es dort warm ist und sie nicht ins Haus wollen. Sie spielen mit dem Ball.", "de"},
+		{"Le chat et le chien sont dans le jardin parce que il fait chaud et ils ne veulent pas entrer dans la maison avec nous.", "fr"},
+		{"El perro y el gato están en el jardín porque hace calor y no quieren entrar en la casa con los niños de la familia.", "es"},
+		{"Кошка и собака сидят в саду, потому что там тепло, и они не хотят заходить в дом вместе с детьми этой семьи сегодня.", "ru"},
+		{"猫と犬は庭にいます。暖かいので、家に入りたくないのです。子供たちと一緒に遊んでいます。", "ja"},
+		{"Widget 12 Widget 13 Gadget 14 Gizmo 15 Sprocket 16 Widget 17 Gadget 18 Gizmo 19 Sprocket 20 Widget 21", ""},
+		{"Too short to tell.", ""},
+	}
+
+	for _, tt := range tests {
+		if got := detectLanguage(tt.text, textWords(tt.text)); got != tt.expected {
+			t.Errorf("Expected %q for %q, got %q", tt.expected, tt.text, got)
+		}
+	}
+}
+
+func TestDetectableLanguage(t *testing.T) {
+	for _, language := range []string{"en", "pl", "ja", "uk"} {
+		if !detectableLanguage(language) {
+			t.Errorf("Expected %q to be detectable", language)
+		}
+	}
+	for _, language := range []string{"", "sv", "tr", "vi"} {
+		if detectableLanguage(language) {
+			t.Errorf("Expected %q not to be detectable", language)
+		}
+	}
+}
+
+func TestReadability_HarderTextScoresLower(t *testing.T) {
+	easy := strings.Repeat("The dog ran to the park. It was fun. ", 5)
+	hard := strings.Repeat("Institutional considerations necessitate comprehensive organizational restructuring, notwithstanding considerable administrative opposition. ", 3)
+
+	easyScore := readability(easy, textWords(easy), "en")
+	hardScore := readability(hard, textWords(hard), "en")
+	if easyScore == nil || hardScore == nil {
+		t.Fatal("Expected both texts to be scored")
+	}
+	if *easyScore <= *hardScore {
+		t.Errorf("Expected easy text (%.1f) to score higher than hard text (%.1f)", *easyScore, *hardScore)
+	}
+	if readability(easy, textWords(easy), "") != nil {
+		t.Error("Expected no score for an unknown language")
+	}
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"sykell-crawler/internal/models"
+)
+
+// profileSecrets are a crawl profile's decrypted cookies and password.
+type profileSecrets struct {
+	cookies  map[string]string
+	password string
+}
+
+// profileTransport adds a crawl profile's User-Agent and headers to every
+// request, and its cookies and basic-auth credentials only to requests with
+// the crawled URL's scheme and host, so they never reach external links and
+// are never sent in cleartext when the crawled URL is HTTPS.
+type profileTransport struct {
+	base    http.RoundTripper
+	profile *models.CrawlProfile
+	secrets profileSecrets
+	scheme  string
+	host    string
+}
+
+func (t *profileTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// A RoundTripper must not modify the request it was given
+	req = req.Clone(req.Context())
+	for name, value := range t.profile.Headers {
+		req.Header.Set(name, value)
+	}
+	if t.profile.UserAgent != "" {
+		req.Header.Set("User-Agent", t.profile.UserAgent)
+	}
+
+	if t.host != "" && strings.EqualFold(req.URL.Scheme, t.scheme) && strings.EqualFold(req.URL.Host, t.host) {
+		names := make([]string, 0, len(t.secrets.cookies))
+		for name := range t.secrets.cookies {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			req.AddCookie(&http.Cookie{Name: name, Value: t.secrets.cookies[name]})
+		}
+		if t.profile.BasicAuthUsername != "" {
+			req.SetBasicAuth(t.profile.BasicAuthUsername, t.secrets.password)
+		}
+	}
+	return t.base.RoundTrip(req)
+}
+
+// withProfile returns a copy of the crawler whose page and link-check clients
+// apply the URL's crawl profile, or the crawler itself when it has none.
+func (s *crawlerService) withProfile(urlModel *models.URL) (*crawlerService, error) {
+	profile := urlModel.CrawlProfile
+	if profile == nil {
+		return s, nil
+	}
+
+	secrets, err := decryptProfileSecrets(newCredentialCipher(s.config), profile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt the credentials of crawl profile %q: %w", profile.Name, err)
+	}
+	site, err := url.Parse(urlModel.URL)
+	if err != nil {
+		site = &url.URL{}
+	}
+
+	crawler := *s
+	crawler.profile = profile
+	crawler.client = profileClient(s.client, profile, secrets, site)
+	if profile.TimeoutSeconds > 0 {
+		crawler.client.Timeout = time.Duration(profile.TimeoutSeconds) * time.Second
+	}
+	crawler.linkCheckClient = profileClient(s.linkCheckClient, profile, secrets, site)
+	return &crawler, nil
+}
+
+func decryptProfileSecrets(credentials *credentialCipher, profile *models.CrawlProfile) (profileSecrets, error) {
+	var secrets profileSecrets
+	if len(profile.Cookies) > 0 {
+		secrets.cookies = make(map[string]string, len(profile.Cookies))
+		for name, sealed := range profile.Cookies {
+			value, err := credentials.decrypt(sealed)
+			if err != nil {
+				return profileSecrets{}, fmt.Errorf("cookie %q: %w", name, err)
+			}
+			secrets.cookies[name] = value
+		}
+	}
+	if profile.BasicAuthPassword != "" {
+		password, err := credentials.decrypt(profile.BasicAuthPassword)
+		if err != nil {
+			return profileSecrets{}, err
+		}
+		secrets.password = password
+	}
+	return secrets, nil
+}
+
+// profileClient copies client with its transport wrapped to apply profile,
+// sending its secrets to the scheme and host of site only.
+func profileClient(client *http.Client, profile *models.CrawlProfile, secrets profileSecrets, site *url.URL) *http.Client {
+	profiled := *client
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	profiled.Transport = &profileTransport{base: base, profile: profile, secrets: secrets, scheme: site.Scheme, host: site.Host}
+	return &profiled
+}
+
+func (s *crawlerService) skipLinkChecks() bool {
+	return s.profile != nil && s.profile.SkipLinkChecks
+}
+
+func (s *crawlerService) skipImageChecks() bool {
+	return s.profile != nil && s.profile.SkipImageChecks
+}
+
+func (s *crawlerService) skipResourceChecks() bool {
+	return s.profile != nil && s.profile.SkipResourceChecks
+}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/http/httpguts"
+	"gorm.io/gorm"
+	"sykell-crawler/internal/models"
+	"sykell-crawler/internal/repositories"
+	"sykell-crawler/pkg/config"
+)
+
+var (
+	// ErrProfileNotFound is returned when a crawl profile does not exist.
+	ErrProfileNotFound = errors.New("crawl profile not found")
+	// ErrInvalidProfile is returned when a crawl profile fails validation.
+	ErrInvalidProfile = errors.New("invalid crawl profile")
+)
+
+// MaxProfileTimeoutSeconds caps the request timeout a profile can set.
+const MaxProfileTimeoutSeconds = 300
+
+// reservedProfileHeaders can't be set as extra headers, either because the
+// profile has a dedicated field for them or because the crawler manages them.
+var reservedProfileHeaders = toSet(
+	"Host", "User-Agent", "Cookie", "Authorization", "Proxy-Authorization",
+	"Accept-Encoding", "Connection", "Content-Length", "Transfer-Encoding",
+)
+
+// CrawlProfileInput describes a crawl profile to create or replace. An empty
+// BasicAuthPassword on update keeps the stored password as long as the
+// username is set, and nil Cookies keep the stored cookies, since neither
+// can be read back. Empty, non-nil Cookies remove them.
+type CrawlProfileInput struct {
+	Name               string
+	UserAgent          string
+	Headers            map[string]string
+	Cookies            map[string]string
+	BasicAuthUsername  string
+	BasicAuthPassword  string
+	TimeoutSeconds     int
+	SkipLinkChecks     bool
+	SkipImageChecks    bool
+	SkipResourceChecks bool
+}
+
+type CrawlProfileService interface {
+	CreateProfile(input CrawlProfileInput) (*models.CrawlProfile, error)
+	ListProfiles() ([]*models.CrawlProfile, error)
+	GetProfile(id uint) (*models.CrawlProfile, error)
+	UpdateProfile(id uint, input CrawlProfileInput) (*models.CrawlProfile, error)
+	DeleteProfile(id uint) error
+}
+
+type crawlProfileService struct {
+	profileRepo repositories.CrawlProfileRepository
+	credentials *credentialCipher
+}
+
+func NewCrawlProfileService(profileRepo repositories.CrawlProfileRepository, cfg *config.Config) CrawlProfileService {
+	return &crawlProfileService{
+		profileRepo: profileRepo,
+		credentials: newCredentialCipher(cfg),
+	}
+}
+
+func (s *crawlProfileService) CreateProfile(input CrawlProfileInput) (*models.CrawlProfile, error) {
+	profile := &models.CrawlProfile{}
+	if err := s.apply(profile, input); err != nil {
+		return nil, err
+	}
+	if err := s.profileRepo.Create(profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+func (s *crawlProfileService) ListProfiles() ([]*models.CrawlProfile, error) {
+	return s.profileRepo.GetAll()
+}
+
+func (s *crawlProfileService) GetProfile(id uint) (*models.CrawlProfile, error) {
+	profile, err := s.profileRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrProfileNotFound
+		}
+		return nil, err
+	}
+	return profile, nil
+}
+
+// UpdateProfile replaces a profile. URLs assigned to it use the new settings
+// from their next crawl.
+func (s *crawlProfileService) UpdateProfile(id uint, input CrawlProfileInput) (*models.CrawlProfile, error) {
+	profile, err := s.GetProfile(id)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.apply(profile, input); err != nil {
+		return nil, err
+	}
+	if err := s.profileRepo.Update(profile); err != nil {
+		return nil, err
+	}
+	return profile, nil
+}
+
+// DeleteProfile deletes a profile. Its URLs are crawled with the default
+// request settings from then on.
+func (s *crawlProfileService) DeleteProfile(id uint) error {
+	if _, err := s.GetProfile(id); err != nil {
+		return err
+	}
+	return s.profileRepo.Delete(id)
+}
+
+// apply validates input and copies it onto profile, encrypting the password
+// and cookie values.
+func (s *crawlProfileService) apply(profile *models.CrawlProfile, input CrawlProfileInput) error {
+	name := strings.TrimSpace(input.Name)
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidProfile)
+	}
+	existing, err := s.profileRepo.GetByName(name)
+	if err == nil && existing.ID != profile.ID {
+		return fmt.Errorf("%w: a profile named %q already exists", ErrInvalidProfile, name)
+	}
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	userAgent := strings.TrimSpace(input.UserAgent)
+	if !httpguts.ValidHeaderFieldValue(userAgent) {
+		return fmt.Errorf("%w: user agent contains invalid characters", ErrInvalidProfile)
+	}
+	headers, err := normalizeProfileHeaders(input.Headers)
+	if err != nil {
+		return err
+	}
+	cookies, cookieNames := profile.Cookies, profile.CookieNames
+	if input.Cookies != nil {
+		if cookies, cookieNames, err = s.encryptProfileCookies(input.Cookies); err != nil {
+			return err
+		}
+	}
+	if input.TimeoutSeconds < 0 || input.TimeoutSeconds > MaxProfileTimeoutSeconds {
+		return fmt.Errorf("%w: timeout must be between 0 and %d seconds", ErrInvalidProfile, MaxProfileTimeoutSeconds)
+	}
+
+	username := strings.TrimSpace(input.BasicAuthUsername)
+	password := profile.BasicAuthPassword
+	switch {
+	case username == "":
+		if input.BasicAuthPassword != "" {
+			return fmt.Errorf("%w: basic auth password requires a username", ErrInvalidProfile)
+		}
+		password = ""
+	case strings.Contains(username, ":"):
+		return fmt.Errorf("%w: basic auth username must not contain a colon", ErrInvalidProfile)
+	case input.BasicAuthPassword != "":
+		if password, err = s.credentials.encrypt(input.BasicAuthPassword); err != nil {
+			return err
+		}
+	case password == "":
+		return fmt.Errorf("%w: basic auth password is required", ErrInvalidProfile)
+	}
+
+	profile.Name = name
+	profile.UserAgent = userAgent
+	profile.Headers = headers
+	profile.Cookies = cookies
+	profile.CookieNames = cookieNames
+	profile.BasicAuthUsername = username
+	profile.BasicAuthPassword = password
+	profile.TimeoutSeconds = input.TimeoutSeconds
+	profile.SkipLinkChecks = input.SkipLinkChecks
+	profile.SkipImageChecks = input.SkipImageChecks
+	profile.SkipResourceChecks = input.SkipResourceChecks
+	return nil
+}
+
+// normalizeProfileHeaders canonicalizes header names and rejects invalid or
+// reserved headers.
+func normalizeProfileHeaders(headers map[string]string) (map[string]string, error) {
+	if len(headers) == 0 {
+		return nil, nil
+	}
+	normalized := make(map[string]string, len(headers))
+	for name, value := range headers {
+		name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+		value = strings.TrimSpace(value)
+		if !httpguts.ValidHeaderFieldName(name) || !httpguts.ValidHeaderFieldValue(value) {
+			return nil, fmt.Errorf("%w: invalid header %q", ErrInvalidProfile, name)
+		}
+		if reservedProfileHeaders[name] {
+			return nil, fmt.Errorf("%w: header %q can't be set on a profile", ErrInvalidProfile, name)
+		}
+		normalized[name] = value
+	}
+	return normalized, nil
+}
+
+// encryptProfileCookies validates cookies and returns them with their values
+// encrypted, along with their sorted names, which are all the API shows.
+func (s *crawlProfileService) encryptProfileCookies(cookies map[string]string) (map[string]string, []string, error) {
+	if len(cookies) == 0 {
+		return nil, nil, nil
+	}
+	encrypted := make(map[string]string, len(cookies))
+	names := make([]string, 0, len(cookies))
+	for name, value := range cookies {
+		cookie := &http.Cookie{Name: strings.TrimSpace(name), Value: value}
+		if err := cookie.Valid(); err != nil {
+			return nil, nil, fmt.Errorf("%w: cookie %q: %v", ErrInvalidProfile, name, err)
+		}
+		sealed, err := s.credentials.encrypt(cookie.Value)
+		if err != nil {
+			return nil, nil, err
+		}
+		encrypted[cookie.Name] = sealed
+		names = append(names, cookie.Name)
+	}
+	sort.Strings(names)
+	return encrypted, names, nil
+}
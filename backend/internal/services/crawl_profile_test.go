@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+	"sykell-crawler/internal/models"
+)
+
+type mockCrawlProfileRepository struct {
+	profiles map[uint]*models.CrawlProfile
+}
+
+func (m *mockCrawlProfileRepository) Create(profile *models.CrawlProfile) error {
+	profile.ID = uint(len(m.profiles) + 1)
+	m.profiles[profile.ID] = profile
+	return nil
+}
+
+func (m *mockCrawlProfileRepository) GetByID(id uint) (*models.CrawlProfile, error) {
+	if profile, exists := m.profiles[id]; exists {
+		return profile, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockCrawlProfileRepository) GetByName(name string) (*models.CrawlProfile, error) {
+	for _, profile := range m.profiles {
+		if profile.Name == name {
+			return profile, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockCrawlProfileRepository) GetAll() ([]*models.CrawlProfile, error) {
+	return nil, nil
+}
+
+func (m *mockCrawlProfileRepository) Update(profile *models.CrawlProfile) error {
+	m.profiles[profile.ID] = profile
+	return nil
+}
+
+func (m *mockCrawlProfileRepository) Delete(id uint) error {
+	delete(m.profiles, id)
+	return nil
+}
+
+func TestCredentialCipher(t *testing.T) {
+	cfg := createTestConfig()
+	cfg.CredentialsKey = "a-credentials-key-that-is-long-enough"
+
+	encrypted, err := newCredentialCipher(cfg).encrypt("s3cret")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if encrypted == "s3cret" {
+		t.Fatal("Expected the password to be encrypted")
+	}
+
+	decrypted, err := newCredentialCipher(cfg).decrypt(encrypted)
+	if err != nil || decrypted != "s3cret" {
+		t.Errorf("Expected the password back, got %q, %v", decrypted, err)
+	}
+
+	cfg.CredentialsKey = "a-different-key-that-is-long-enough"
+	if _, err := newCredentialCipher(cfg).decrypt(encrypted); err == nil {
+		t.Error("Expected decryption with another key to fail")
+	}
+}
+
+func TestCrawlProfileService_Validation(t *testing.T) {
+	profileRepo := &mockCrawlProfileRepository{profiles: make(map[uint]*models.CrawlProfile)}
+	service := NewCrawlProfileService(profileRepo, createTestConfig())
+
+	invalid := map[string]CrawlProfileInput{
+		"blank name":           {Name: " "},
+		"reserved header":      {Name: "staging", Headers: map[string]string{"authorization": "Bearer token"}},
+		"invalid header":       {Name: "staging", Headers: map[string]string{"X Bad": "1"}},
+		"invalid cookie":       {Name: "staging", Cookies: map[string]string{"session": "a;b"}},
+		"timeout too long":     {Name: "staging", TimeoutSeconds: MaxProfileTimeoutSeconds + 1},
+		"password only":        {Name: "staging", BasicAuthPassword: "secret"},
+		"username only":        {Name: "staging", BasicAuthUsername: "admin"},
+		"colon in username":    {Name: "staging", BasicAuthUsername: "ad:min", BasicAuthPassword: "secret"},
+		"user agent line feed": {Name: "staging", UserAgent: "bot\nX-Injected: 1"},
+	}
+	for name, input := range invalid {
+		if _, err := service.CreateProfile(input); !errors.Is(err, ErrInvalidProfile) {
+			t.Errorf("%s: expected ErrInvalidProfile, got %v", name, err)
+		}
+	}
+
+	profile, err := service.CreateProfile(CrawlProfileInput{
+		Name:              " staging ",
+		Headers:           map[string]string{"accept-language": " de-DE "},
+		Cookies:           map[string]string{"session": "abc", " consent": "1"},
+		BasicAuthUsername: "admin",
+		BasicAuthPassword: "secret",
+	})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if profile.Name != "staging" || profile.Headers["Accept-Language"] != "de-DE" {
+		t.Errorf("Expected the profile to be normalized, got %+v", profile)
+	}
+	if profile.BasicAuthPassword == "" || profile.BasicAuthPassword == "secret" {
+		t.Errorf("Expected the password to be stored encrypted, got %q", profile.BasicAuthPassword)
+	}
+	if session := profile.Cookies["session"]; session == "" || session == "abc" {
+		t.Errorf("Expected the cookie values to be stored encrypted, got %q", session)
+	}
+	if strings.Join(profile.CookieNames, ",") != "consent,session" {
+		t.Errorf("Expected the cookie names to be listed, got %v", profile.CookieNames)
+	}
+
+	if _, err := service.CreateProfile(CrawlProfileInput{Name: "staging"}); !errors.Is(err, ErrInvalidProfile) {
+		t.Errorf("Expected a duplicate name to be rejected, got %v", err)
+	}
+
+	// Updating without a password or cookies keeps the stored ones
+	stored, storedSession := profile.BasicAuthPassword, profile.Cookies["session"]
+	updated, err := service.UpdateProfile(profile.ID, CrawlProfileInput{Name: "staging", BasicAuthUsername: "admin", TimeoutSeconds: 60})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.BasicAuthPassword != stored || updated.Cookies["session"] != storedSession || updated.TimeoutSeconds != 60 {
+		t.Errorf("Expected the stored password and cookies to be kept, got %+v", updated)
+	}
+
+	updated, err = service.UpdateProfile(profile.ID, CrawlProfileInput{Name: "staging", Cookies: map[string]string{}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if updated.Cookies != nil || updated.CookieNames != nil {
+		t.Errorf("Expected empty cookies to remove the stored ones, got %+v", updated)
+	}
+
+	if _, err := service.UpdateProfile(42, CrawlProfileInput{Name: "other"}); !errors.Is(err, ErrProfileNotFound) {
+		t.Errorf("Expected ErrProfileNotFound, got %v", err)
+	}
+}
+
+func TestCrawlURL_AppliesCrawlProfile(t *testing.T) {
+	var mu sync.Mutex
+	var externalRequests []*http.Request
+	external := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			return
+		}
+		mu.Lock()
+		externalRequests = append(externalRequests, r)
+		mu.Unlock()
+	}))
+	defer external.Close()
+
+	site := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		session, err := r.Cookie("session")
+		if !ok || username != "admin" || password != "s3cret" || err != nil || session.Value != "abc" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get("User-Agent") != "ProfileBot/1.0" || r.Header.Get("Accept-Language") != "de" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		fmt.Fprintf(w, `<html><head><title>Staging</title></head><body>
+<a href="/about">About</a>
+<a href="%s/partner">Partner</a>
+<img src="%s/logo.png" alt="">
+<script src="/app.js"></script>
+</body></html>`, external.URL, external.URL)
+	}))
+	defer site.Close()
+
+	cfg := createTestConfig()
+	password, err := newCredentialCipher(cfg).encrypt("s3cret")
+	if err != nil {
+		t.Fatalf("Failed to encrypt password: %v", err)
+	}
+	session, err := newCredentialCipher(cfg).encrypt("abc")
+	if err != nil {
+		t.Fatalf("Failed to encrypt cookie: %v", err)
+	}
+	profile := &models.CrawlProfile{
+		ID:                1,
+		Name:              "staging",
+		UserAgent:         "ProfileBot/1.0",
+		Headers:           map[string]string{"Accept-Language": "de"},
+		Cookies:           map[string]string{"session": session},
+		BasicAuthUsername: "admin",
+		BasicAuthPassword: password,
+	}
+
+	crawl := func() *models.CrawlResult {
+		urlRepo := &mockURLRepository{urls: map[uint]*models.URL{
+			1: {ID: 1, URL: site.URL, Status: models.StatusQueued, CrawlProfileID: &profile.ID, CrawlProfile: profile},
+		}}
+		resultRepo := &mockCrawlResultRepository{results: make(map[uint]*models.CrawlResult)}
+		service := NewCrawlerService(urlRepo, resultRepo, nil, &mockQueueService{}, cfg)
+		if err := service.CrawlURL(context.Background(), 1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		return resultRepo.results[1]
+	}
+
+	result := crawl()
+	if result.Title != "Staging" {
+		t.Fatalf("Expected the page to be fetched with the profile, got %+v", result)
+	}
+	if result.BrokenLinks != 0 || result.BrokenResources != 0 || result.BrokenImages != 0 {
+		t.Errorf("Expected link checks on the site to be authenticated, got %+v", result.BrokenURLs)
+	}
+	if len(externalRequests) != 2 {
+		t.Fatalf("Expected the partner link and logo to be checked, got %d requests", len(externalRequests))
+	}
+	for _, r := range externalRequests {
+		if r.Header.Get("User-Agent") != "ProfileBot/1.0" || r.Header.Get("Accept-Language") != "de" {
+			t.Errorf("Expected link checks to send the profile's headers, got %v", r.Header)
+		}
+		if r.Header.Get("Authorization") != "" || r.Header.Get("Cookie") != "" {
+			t.Errorf("Expected no credentials to be sent to another host, got %v", r.Header)
+		}
+	}
+
+	externalRequests = nil
+	profile.SkipLinkChecks = true
+	profile.SkipImageChecks = true
+	profile.SkipResourceChecks = true
+	result = crawl()
+	if len(externalRequests) != 0 {
+		t.Errorf("Expected no link checks, got %d requests", len(externalRequests))
+	}
+	for _, link := range result.Links {
+		if link.CheckStatus != models.LinkUnchecked {
+			t.Errorf("Expected %s to be unchecked, got %s", link.URL, link.CheckStatus)
+		}
+	}
+	if len(result.Resources) != 1 || result.Resources[0].StatusCode != 0 {
+		t.Errorf("Expected the script to be listed unchecked, got %+v", result.Resources)
+	}
+}
+
+type recordingTransport struct {
+	requests []*http.Request
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.requests = append(t.requests, req)
+	return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Request: req}, nil
+}
+
+func TestProfileTransport_SendsSecretsOnlyToCrawledOrigin(t *testing.T) {
+	recorder := &recordingTransport{}
+	profile := &models.CrawlProfile{Name: "staging", BasicAuthUsername: "admin"}
+	site, _ := url.Parse("https://staging.example.com/")
+	client := profileClient(&http.Client{Transport: recorder}, profile, profileSecrets{
+		cookies:  map[string]string{"session": "abc"},
+		password: "s3cret",
+	}, site)
+
+	for _, target := range []string{"https://staging.example.com/page", "http://staging.example.com/page", "https://other.example.com/"} {
+		resp, err := client.Get(target)
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		resp.Body.Close()
+	}
+
+	for i, req := range recorder.requests {
+		_, _, hasAuth := req.BasicAuth()
+		_, cookieErr := req.Cookie("session")
+		if expected := i == 0; hasAuth != expected || (cookieErr == nil) != expected {
+			t.Errorf("%s: expected credentials %v, got auth %v and cookie error %v", req.URL, expected, hasAuth, cookieErr)
+		}
+	}
+}
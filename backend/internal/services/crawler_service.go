@@ -9,45 +9,97 @@ import (
 	"sykell-crawler/internal/models"
 	"sykell-crawler/internal/repositories"
 	"sykell-crawler/pkg/config"
+	"time"
+	"unicode/utf8"
 
 	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
 )
 
 type CrawlerService interface {
 	CrawlURL(ctx context.Context, urlID uint) error
+	CrawlSite(ctx context.Context, job CrawlJob) error
 }
 
 type crawlerService struct {
-	urlRepo          repositories.URLRepository
-	resultRepo       repositories.CrawlResultRepository
-	client           *http.Client
-	queue            QueueService
-	config           *config.Config
-	linkCheckClient  *http.Client
+	urlRepo         repositories.URLRepository
+	resultRepo      repositories.CrawlResultRepository
+	ruleRepo        repositories.ExtractionRuleRepository
+	client          *http.Client
+	queue           QueueService
+	config          *config.Config
+	linkCheckClient *http.Client
+	robots          *robotsCache
+	analyzers       *AnalyzerRegistry
+	profile         *models.CrawlProfile
 }
 
-func NewCrawlerService(urlRepo repositories.URLRepository, resultRepo repositories.CrawlResultRepository, queue QueueService, cfg *config.Config) CrawlerService {
+func NewCrawlerService(urlRepo repositories.URLRepository, resultRepo repositories.CrawlResultRepository, ruleRepo repositories.ExtractionRuleRepository, queue QueueService, cfg *config.Config) CrawlerService {
+	// Every request identifies as the agent robots.txt rules are matched for
+	transport := &userAgentTransport{base: http.DefaultTransport, userAgent: cfg.RobotsUserAgent}
+	linkCheckClient := &http.Client{
+		Transport: transport,
+		Timeout:   cfg.LinkCheckTimeout,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= 5 {
+				return http.ErrUseLastResponse
+			}
+			return nil
+		},
+	}
+
+	robotsTTL := cfg.RobotsCacheTTL
+	if robotsTTL <= 0 {
+		robotsTTL = 24 * time.Hour
+	}
+
 	return &crawlerService{
 		urlRepo:    urlRepo,
 		resultRepo: resultRepo,
+		ruleRepo:   ruleRepo,
 		queue:      queue,
 		config:     cfg,
 		client: &http.Client{
-			Timeout: cfg.HTTPTimeout,
-		},
-		linkCheckClient: &http.Client{
-			Timeout: cfg.LinkCheckTimeout,
+			Transport: transport,
+			Timeout:   cfg.HTTPTimeout,
+			// Redirects are followed by fetchPage so each hop can be recorded
 			CheckRedirect: func(req *http.Request, via []*http.Request) error {
-				if len(via) >= 5 {
-					return http.ErrUseLastResponse
-				}
-				return nil
+				return http.ErrUseLastResponse
 			},
 		},
+		linkCheckClient: linkCheckClient,
+		robots:          newRobotsCache(linkCheckClient, cfg.RobotsUserAgent, robotsTTL),
+		analyzers:       defaultAnalyzers,
+	}
+}
+
+// userAgentTransport sets the crawler's User-Agent on requests that don't
+// already carry one, such as those of a crawl profile.
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.userAgent != "" && req.Header.Get("User-Agent") == "" {
+		// A RoundTripper must not modify the request it was given
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
 	}
+	return t.base.RoundTrip(req)
 }
 
 func (s *crawlerService) CrawlURL(ctx context.Context, urlID uint) error {
+	return s.crawl(ctx, CrawlJob{URLID: urlID, Mode: models.CrawlModePage})
+}
+
+func (s *crawlerService) CrawlSite(ctx context.Context, job CrawlJob) error {
+	job.Mode = models.CrawlModeSite
+	return s.crawl(ctx, job)
+}
+
+func (s *crawlerService) crawl(ctx context.Context, job CrawlJob) error {
+	urlID := job.URLID
 	urlModel, err := s.urlRepo.GetByID(urlID)
 	if err != nil {
 		return err
@@ -63,11 +115,18 @@ func (s *crawlerService) CrawlURL(ctx context.Context, urlID uint) error {
 		return nil
 	}
 
+	crawler, err := s.withProfile(urlModel)
+	if err != nil {
+		urlModel.Status = models.StatusError
+		urlModel.ErrorMessage = err.Error()
+		return s.urlRepo.Update(urlModel)
+	}
+
 	if err := s.urlRepo.UpdateStatus(urlID, models.StatusRunning); err != nil {
 		return err
 	}
 
-	result, err := s.performCrawl(urlModel.URL, urlID)
+	result, internalURLs, err := crawler.performCrawl(urlModel.URL, urlModel, nil)
 	if err != nil {
 		// Check if error was due to job being stopped
 		if cancelled, checkErr := s.queue.IsCancelled(urlID); checkErr == nil && cancelled {
@@ -78,11 +137,19 @@ func (s *crawlerService) CrawlURL(ctx context.Context, urlID uint) error {
 			urlModel.ErrorMessage = err.Error()
 		}
 		s.urlRepo.Update(urlModel)
-		result = &models.CrawlResult{
-			URLID:        urlID,
-			ErrorMessage: err.Error(),
-		}
+		result = failedResult(err)
+		result.PageURL = urlModel.URL
+	} else if result.UnsupportedContent {
+		urlModel.Status = models.StatusUnsupported
+		urlModel.Title = ""
+		urlModel.ErrorMessage = result.ErrorMessage
+		s.urlRepo.Update(urlModel)
 	} else {
+		if job.Mode == models.CrawlModeSite {
+			result.Pages = crawler.crawlSitePages(ctx, job, urlModel, result.FinalURL, internalURLs)
+			result.PagesCrawled = len(result.Pages) + 1
+		}
+
 		urlModel.Status = models.StatusDone
 		urlModel.Title = result.Title
 		urlModel.ErrorMessage = "" // Clear any previous error
@@ -93,65 +160,191 @@ func (s *crawlerService) CrawlURL(ctx context.Context, urlID uint) error {
 	return s.resultRepo.Upsert(result)
 }
 
-func (s *crawlerService) performCrawl(targetURL string, urlID uint) (*models.CrawlResult, error) {
+// performCrawl fetches and analyzes a single page. Alongside the result it
+// returns the page's internal links so site crawls can extend their frontier.
+// Links are classified by scope, or when it is nil by the URL's scope rules
+// anchored at the URL the page was served from.
+func (s *crawlerService) performCrawl(targetURL string, urlModel *models.URL, scope *siteScope) (*models.CrawlResult, []string, error) {
+	urlID := urlModel.ID
+	robots := s.robotsPolicyFor(urlModel)
+
 	// Check if job was stopped before starting HTTP request
 	if cancelled, err := s.queue.IsCancelled(urlID); err == nil && cancelled {
-		return nil, fmt.Errorf("crawl stopped")
+		return nil, nil, fmt.Errorf("crawl stopped")
 	}
 
-	resp, err := s.client.Get(targetURL)
+	fetch, err := s.fetchPage(targetURL, robots)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch URL: %w", err)
+		return nil, nil, err
 	}
+	resp := fetch.Response
 	defer resp.Body.Close()
 
 	// Check if job was stopped after HTTP request
 	if cancelled, err := s.queue.IsCancelled(urlID); err == nil && cancelled {
-		return nil, fmt.Errorf("crawl stopped")
+		return nil, nil, fmt.Errorf("crawl stopped")
 	}
 
 	if resp.StatusCode >= 400 {
-		return nil, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+		return nil, nil, withFetch(fmt.Errorf("HTTP error: %d", resp.StatusCode), fetch)
 	}
 
-	doc, err := goquery.NewDocumentFromReader(resp.Body)
+	content, err := decodePageContent(resp)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse HTML: %w", err)
+		return nil, nil, withFetch(fmt.Errorf("failed to read response: %w", err), fetch)
 	}
 
+	// Relative references resolve against the URL the page was served from
+	pageURL := fetch.FinalURL
+
 	result := &models.CrawlResult{
-		HTMLVersion:   s.extractHTMLVersion(doc),
-		Title:         s.extractTitle(doc),
-		H1Count:       s.countHeadings(doc, "h1"),
-		H2Count:       s.countHeadings(doc, "h2"),
-		H3Count:       s.countHeadings(doc, "h3"),
-		H4Count:       s.countHeadings(doc, "h4"),
-		H5Count:       s.countHeadings(doc, "h5"),
-		H6Count:       s.countHeadings(doc, "h6"),
-		HasLoginForm:  s.detectLoginForm(doc),
+		PageURL:       targetURL,
+		FinalURL:      pageURL,
+		RedirectCount: len(fetch.Redirects),
+		Redirects:     fetch.Redirects,
+		ContentType:   content.MIMEType,
+		Charset:       content.Charset,
+		SecurityAudit: auditSecurityHeaders(resp.Header, strings.HasPrefix(pageURL, "https://")),
+	}
+	setTLSInfo(result, fetch.TLS)
+
+	if !content.HTML {
+		// Don't download the rest of a PDF or image just to discard it
+		resp.Body.Close()
+		fetch.Timing.apply(result)
+		result.UnsupportedContent = true
+		result.ErrorMessage = fmt.Sprintf("unsupported content type: %s", content.MIMEType)
+		return result, nil, nil
 	}
 
-	internalLinks, externalLinks, brokenURLs := s.analyzeLinks(doc, targetURL)
-	result.InternalLinks = internalLinks
-	result.ExternalLinks = externalLinks
-	result.BrokenLinks = len(brokenURLs)
-	result.BrokenURLs = brokenURLs
+	doc, err := goquery.NewDocumentFromReader(content.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse HTML: %w", err)
+	}
+	fetch.Timing.apply(result)
+
+	result.HTMLVersion, result.DoctypePublicID, result.DoctypeSystemID = s.extractHTMLVersion(doc)
+	result.Title = s.extractTitle(doc)
+	result.H1Count = s.countHeadings(doc, "h1")
+	result.H2Count = s.countHeadings(doc, "h2")
+	result.H3Count = s.countHeadings(doc, "h3")
+	result.H4Count = s.countHeadings(doc, "h4")
+	result.H5Count = s.countHeadings(doc, "h5")
+	result.H6Count = s.countHeadings(doc, "h6")
+	result.Headings, result.HeadingIssues = extractHeadingOutline(doc)
+	result.SEO = extractSEOMetadata(doc, pageURL)
+
+	if scope == nil {
+		site, _ := url.Parse(pageURL)
+		scope = newSiteScope(site, urlModel.ScopeMode, urlModel.ScopeHosts)
+	}
+	links := s.analyzeLinks(doc, pageURL, scope, robots)
+	result.InternalLinks = links.InternalCount
+	result.ExternalLinks = links.ExternalCount
+	result.BrokenLinks = len(links.BrokenURLs)
+	result.Links = links.Links
+	result.BrokenURLs = links.BrokenURLs
+	result.BlockedLinks = len(links.BlockedURLs)
+	result.BlockedURLs = links.BlockedURLs
+
+	stats := analyzeContent(doc, result.UncompressedBytes)
+	result.WordCount = stats.WordCount
+	result.TextRatio = stats.TextRatio
+	result.ContentLanguage = stats.Language
+	result.Readability = stats.Readability
+	if declared := primaryLanguage(result.SEO.Lang); detectableLanguage(declared) && stats.Language != "" {
+		result.LangMismatch = declared != stats.Language
+	}
+	result.ContentSimHash = stats.SimHash
+	result.ContentHash = textHash(stats.Text)
+	result.TitleHash = textHash(result.Title)
+	result.MetaDescriptionHash = textHash(result.SEO.MetaDescription)
+
+	// Forms, images, resources, mixed content, structured data, accessibility
+	// and extraction rules are analyzers, so they can be turned off
+	result.Findings = s.runAnalyzers(&AnalyzerInput{
+		URL:      urlModel,
+		PageURL:  pageURL,
+		Response: resp,
+		Document: doc,
+		Result:   result,
+		crawler:  s,
+		robots:   robots,
+	})
+
+	return result, links.InternalURLs, nil
+}
 
-	return result, nil
+// doctypeVersions maps lower-cased DOCTYPE public identifier prefixes to the
+// HTML version they declare. Order matters: more specific prefixes come first.
+var doctypeVersions = []struct {
+	publicPrefix string
+	version      string
+}{
+	{"-//w3c//dtd xhtml 1.1//", "XHTML 1.1"},
+	{"-//w3c//dtd xhtml 1.0 strict//", "XHTML 1.0 Strict"},
+	{"-//w3c//dtd xhtml 1.0 transitional//", "XHTML 1.0 Transitional"},
+	{"-//w3c//dtd xhtml 1.0 frameset//", "XHTML 1.0 Frameset"},
+	{"-//w3c//dtd html 4.01 transitional//", "HTML 4.01 Transitional"},
+	{"-//w3c//dtd html 4.01 frameset//", "HTML 4.01 Frameset"},
+	{"-//w3c//dtd html 4.01//", "HTML 4.01 Strict"},
+	{"-//w3c//dtd html 4.0 transitional//", "HTML 4.0 Transitional"},
+	{"-//w3c//dtd html 4.0 frameset//", "HTML 4.0 Frameset"},
+	{"-//w3c//dtd html 4.0//", "HTML 4.0 Strict"},
+	{"-//w3c//dtd html 3.2", "HTML 3.2"},
+	{"-//ietf//dtd html 2.0", "HTML 2.0"},
 }
 
-func (s *crawlerService) extractHTMLVersion(doc *goquery.Document) string {
-	doctype := doc.Find("html").AttrOr("", "")
-	if doctype == "" {
-		return "HTML5"
+const (
+	htmlVersionQuirks  = "Quirks mode (no DOCTYPE)"
+	htmlVersionUnknown = "Unknown"
+)
+
+// extractHTMLVersion reads the DOCTYPE token of the parsed document and returns
+// the declared HTML version together with its public and system identifiers.
+func (s *crawlerService) extractHTMLVersion(doc *goquery.Document) (version, publicID, systemID string) {
+	var doctype *html.Node
+	for _, root := range doc.Nodes {
+		for n := root.FirstChild; n != nil; n = n.NextSibling {
+			if n.Type == html.DoctypeNode {
+				doctype = n
+				break
+			}
+		}
+	}
+	if doctype == nil {
+		return htmlVersionQuirks, "", ""
+	}
+
+	for _, attr := range doctype.Attr {
+		switch attr.Key {
+		case "public":
+			publicID = attr.Val
+		case "system":
+			systemID = attr.Val
+		}
+	}
+
+	if doctype.Data != "html" {
+		return htmlVersionUnknown, publicID, systemID
+	}
+
+	if publicID == "" {
+		// <!DOCTYPE html> and the XSLT-compatible legacy form both declare HTML5
+		if systemID == "" || strings.EqualFold(systemID, "about:legacy-compat") {
+			return "HTML5", publicID, systemID
+		}
+		return htmlVersionUnknown, publicID, systemID
 	}
-	
-	html := doc.Find("html").First()
-	if html.Length() == 0 {
-		return "Unknown"
+
+	lowerPublic := strings.ToLower(publicID)
+	for _, dv := range doctypeVersions {
+		if strings.HasPrefix(lowerPublic, dv.publicPrefix) {
+			return dv.version, publicID, systemID
+		}
 	}
-	
-	return "HTML5"
+
+	return htmlVersionUnknown, publicID, systemID
 }
 
 func (s *crawlerService) extractTitle(doc *goquery.Document) string {
@@ -162,70 +355,172 @@ func (s *crawlerService) countHeadings(doc *goquery.Document, tag string) int {
 	return doc.Find(tag).Length()
 }
 
-func (s *crawlerService) detectLoginForm(doc *goquery.Document) bool {
-	hasPasswordField := doc.Find("input[type='password']").Length() > 0
-	hasLoginKeywords := false
-	
-	doc.Find("form").Each(func(_ int, form *goquery.Selection) {
-		formHTML := form.Text()
-		lowerHTML := strings.ToLower(formHTML)
-		if strings.Contains(lowerHTML, "login") || 
-		   strings.Contains(lowerHTML, "sign in") || 
-		   strings.Contains(lowerHTML, "log in") {
-			hasLoginKeywords = true
-		}
-	})
-	
-	return hasPasswordField && hasLoginKeywords
+// maxAnchorTextLength caps the stored anchor text of a link in runes.
+const maxAnchorTextLength = 500
+
+// maxLinkTargetLength and maxLinkSchemeLength match the sizes of the target
+// and scheme columns of a link, since both come straight from the page.
+const (
+	maxLinkTargetLength = 64
+	maxLinkSchemeLength = 32
+)
+
+// linkAnalysis summarizes the anchors found on a page. The counts and URL
+// lists are per distinct URL; Links has every anchor.
+type linkAnalysis struct {
+	InternalCount int
+	ExternalCount int
+	InternalURLs  []string
+	Links         []models.Link
+	BrokenURLs    []models.BrokenURL
+	BlockedURLs   []models.BlockedURL
 }
 
-func (s *crawlerService) analyzeLinks(doc *goquery.Document, baseURL string) (int, int, []models.BrokenURL) {
+// analyzeLinks records every anchor on the page and checks the distinct
+// http(s) URLs they point to, counting those within scope as internal. Links
+// to other schemes and to fragments of the page itself are recorded as
+// unchecked and left out of the counts.
+func (s *crawlerService) analyzeLinks(doc *goquery.Document, baseURL string, scope *siteScope, robots robotsPolicy) linkAnalysis {
+	var analysis linkAnalysis
+
 	parsedBase, err := url.Parse(baseURL)
 	if err != nil {
-		return 0, 0, nil
+		return analysis
 	}
 
-	var internalCount, externalCount int
-	var brokenURLs []models.BrokenURL
+	var toCheck []string
 	checkedURLs := make(map[string]bool)
+	blocked := make(map[string]bool)
 
-	doc.Find("a[href]").Each(func(_ int, link *goquery.Selection) {
-		href, exists := link.Attr("href")
-		if !exists || href == "" {
+	doc.Find("a[href]").Each(func(_ int, anchor *goquery.Selection) {
+		href := strings.TrimSpace(anchor.AttrOr("href", ""))
+		if href == "" {
 			return
 		}
 
+		link := models.Link{
+			Position:    len(analysis.Links) + 1,
+			URL:         href,
+			AnchorText:  anchorText(anchor),
+			Rel:         strings.ToLower(strings.Join(strings.Fields(anchor.AttrOr("rel", "")), " ")),
+			Nofollow:    hasRel(anchor, "nofollow"),
+			Sponsored:   hasRel(anchor, "sponsored"),
+			UGC:         hasRel(anchor, "ugc"),
+			Target:      truncateRunes(strings.TrimSpace(anchor.AttrOr("target", "")), maxLinkTargetLength),
+			CheckStatus: models.LinkUnchecked,
+		}
+
 		parsedHref, err := url.Parse(href)
 		if err != nil {
+			link.CheckStatus = models.LinkInvalid
+			link.ErrorMessage = err.Error()
+			analysis.Links = append(analysis.Links, link)
 			return
 		}
+		absolute := parsedBase.ResolveReference(parsedHref)
+		link.URL = absolute.String()
+
+		switch {
+		case strings.HasPrefix(href, "#"):
+			link.Scheme = models.LinkSchemeFragment
+			link.Internal = true
+		case absolute.Scheme != "http" && absolute.Scheme != "https":
+			link.Scheme = truncateRunes(strings.ToLower(absolute.Scheme), maxLinkSchemeLength)
+		default:
+			link.Scheme = absolute.Scheme
+			link.Internal = scope.contains(absolute)
+		}
+		analysis.Links = append(analysis.Links, link)
 
-		absoluteURL := parsedBase.ResolveReference(parsedHref).String()
-		
-		if checkedURLs[absoluteURL] {
+		if link.Scheme != "http" && link.Scheme != "https" {
 			return
 		}
-		checkedURLs[absoluteURL] = true
+		if checkedURLs[link.URL] {
+			return
+		}
+		checkedURLs[link.URL] = true
 
-		if parsedHref.Host == "" || parsedHref.Host == parsedBase.Host {
-			internalCount++
+		if link.Internal {
+			analysis.InternalCount++
+			analysis.InternalURLs = append(analysis.InternalURLs, link.URL)
 		} else {
-			externalCount++
+			analysis.ExternalCount++
+		}
+
+		if !robots.allowed(link.URL) {
+			analysis.BlockedURLs = append(analysis.BlockedURLs, models.BlockedURL{Kind: models.BlockedLink, URL: link.URL})
+			blocked[link.URL] = true
+			return
+		}
+		if !s.skipLinkChecks() {
+			toCheck = append(toCheck, link.URL)
 		}
+	})
 
-		if statusCode, err := s.checkURL(absoluteURL); err != nil || statusCode >= 400 {
+	results := make(map[string]linkStatus, len(toCheck))
+	for _, status := range s.checkURLs(toCheck, robots) {
+		results[status.URL] = status
+		if status.Err != nil || status.StatusCode >= 400 {
 			brokenURL := models.BrokenURL{
-				URL:        absoluteURL,
-				StatusCode: statusCode,
+				URL:        status.URL,
+				StatusCode: status.StatusCode,
 			}
-			if err != nil {
-				brokenURL.ErrorMessage = err.Error()
+			if status.Err != nil {
+				brokenURL.ErrorMessage = status.Err.Error()
 			}
-			brokenURLs = append(brokenURLs, brokenURL)
+			analysis.BrokenURLs = append(analysis.BrokenURLs, brokenURL)
 		}
-	})
+	}
 
-	return internalCount, externalCount, brokenURLs
+	for i := range analysis.Links {
+		link := &analysis.Links[i]
+		if link.Scheme != "http" && link.Scheme != "https" {
+			continue
+		}
+		status, checked := results[link.URL]
+		switch {
+		case !checked && !blocked[link.URL]:
+			// Link checks are turned off by the URL's crawl profile
+			link.CheckStatus = models.LinkUnchecked
+		case !checked:
+			link.CheckStatus = models.LinkBlocked
+		case status.Err != nil:
+			link.CheckStatus = models.LinkBroken
+			link.ErrorMessage = status.Err.Error()
+		case status.StatusCode >= 400:
+			link.CheckStatus = models.LinkBroken
+			link.StatusCode = status.StatusCode
+		default:
+			link.CheckStatus = models.LinkOK
+			link.StatusCode = status.StatusCode
+		}
+	}
+
+	return analysis
+}
+
+// anchorText is the visible text of a link, or for image links the alt text,
+// falling back to aria-label and title.
+func anchorText(anchor *goquery.Selection) string {
+	text := strings.Join(strings.Fields(anchor.Text()), " ")
+	if text == "" {
+		anchor.Find("img[alt]").EachWithBreak(func(_ int, img *goquery.Selection) bool {
+			text = strings.TrimSpace(img.AttrOr("alt", ""))
+			return text == ""
+		})
+	}
+	if text == "" {
+		text = strings.TrimSpace(anchor.AttrOr("aria-label", anchor.AttrOr("title", "")))
+	}
+	return truncateRunes(text, maxAnchorTextLength)
+}
+
+// truncateRunes shortens text to at most max runes.
+func truncateRunes(text string, max int) string {
+	if utf8.RuneCountInString(text) <= max {
+		return text
+	}
+	return string([]rune(text)[:max])
 }
 
 func (s *crawlerService) checkURL(targetURL string) (int, error) {
@@ -5,10 +5,15 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"strings"
 	"sykell-crawler/internal/models"
+	"sykell-crawler/internal/repositories"
 	"sykell-crawler/pkg/config"
 	"testing"
 	"time"
+
+	"github.com/PuerkitoBio/goquery"
 )
 
 type mockURLRepository struct {
@@ -44,6 +49,10 @@ func (m *mockURLRepository) Update(url *models.URL) error {
 	return nil
 }
 
+func (m *mockURLRepository) UpdateColumns(url *models.URL, columns ...string) error {
+	return m.Update(url)
+}
+
 func (m *mockURLRepository) GetByURL(url string) (*models.URL, error) {
 	return nil, errors.New("not implemented")
 }
@@ -61,6 +70,20 @@ func (m *mockURLRepository) GetAll(offset, limit int, search, sortBy, sortOrder
 }
 
 func (m *mockURLRepository) GetByIDs(ids []uint) ([]*models.URL, error) {
+	var urls []*models.URL
+	for _, id := range ids {
+		if url, exists := m.urls[id]; exists {
+			urls = append(urls, url)
+		}
+	}
+	return urls, nil
+}
+
+func (m *mockURLRepository) GetLinks(urlID uint, filter repositories.LinkFilter, offset, limit int) ([]*models.Link, int64, error) {
+	return nil, 0, nil
+}
+
+func (m *mockURLRepository) GetFingerprints() ([]*repositories.URLFingerprint, error) {
 	return nil, nil
 }
 
@@ -109,6 +132,10 @@ func (m *mockQueueService) EnqueueCrawlJob(urlID uint) error {
 	return nil
 }
 
+func (m *mockQueueService) EnqueueSiteCrawlJob(urlID uint, maxDepth, maxPages int) error {
+	return nil
+}
+
 func (m *mockQueueService) ProcessCrawlJobs(ctx context.Context, crawlerService CrawlerService) error {
 	return nil
 }
@@ -137,7 +164,7 @@ func TestNewCrawlerService(t *testing.T) {
 	resultRepo := &mockCrawlResultRepository{}
 	queue := &mockQueueService{}
 	
-	service := NewCrawlerService(urlRepo, resultRepo, queue, createTestConfig())
+	service := NewCrawlerService(urlRepo, resultRepo, nil, queue, createTestConfig())
 	if service == nil {
 		t.Error("Expected non-nil service")
 	}
@@ -175,7 +202,7 @@ func TestCrawlURL_Success(t *testing.T) {
 	}
 	queue := &mockQueueService{cancelled: false}
 
-	service := NewCrawlerService(urlRepo, resultRepo, queue, createTestConfig())
+	service := NewCrawlerService(urlRepo, resultRepo, nil, queue, createTestConfig())
 	err := service.CrawlURL(context.Background(), 1)
 
 	if err != nil {
@@ -202,6 +229,10 @@ func TestCrawlURL_Success(t *testing.T) {
 	if result.H2Count != 1 {
 		t.Errorf("Expected 1 H2 tag, got %d", result.H2Count)
 	}
+
+	if result.HTMLVersion != "HTML5" {
+		t.Errorf("Expected HTML version 'HTML5', got '%s'", result.HTMLVersion)
+	}
 }
 
 func TestCrawlURL_URLNotFound(t *testing.T) {
@@ -213,7 +244,7 @@ func TestCrawlURL_URLNotFound(t *testing.T) {
 	}
 	queue := &mockQueueService{}
 
-	service := NewCrawlerService(urlRepo, resultRepo, queue, createTestConfig())
+	service := NewCrawlerService(urlRepo, resultRepo, nil, queue, createTestConfig())
 	err := service.CrawlURL(context.Background(), 999)
 
 	if err == nil {
@@ -236,7 +267,7 @@ func TestCrawlURL_Cancelled(t *testing.T) {
 	}
 	queue := &mockQueueService{cancelled: true}
 
-	service := NewCrawlerService(urlRepo, resultRepo, queue, createTestConfig())
+	service := NewCrawlerService(urlRepo, resultRepo, nil, queue, createTestConfig())
 	err := service.CrawlURL(context.Background(), 1)
 
 	if err != nil {
@@ -268,7 +299,7 @@ func TestCrawlURL_HTTPError(t *testing.T) {
 	}
 	queue := &mockQueueService{cancelled: false}
 
-	service := NewCrawlerService(urlRepo, resultRepo, queue, createTestConfig())
+	service := NewCrawlerService(urlRepo, resultRepo, nil, queue, createTestConfig())
 	err := service.CrawlURL(context.Background(), 1)
 
 	if err != nil {
@@ -293,3 +324,128 @@ func TestCrawlURL_HTTPError(t *testing.T) {
 		t.Error("Expected result error message to be set")
 	}
 }
+
+
+func TestExtractHTMLVersion(t *testing.T) {
+	tests := []struct {
+		name     string
+		html     string
+		version  string
+		publicID string
+	}{
+		{"HTML5", `<!DOCTYPE html><html><head></head></html>`, "HTML5", ""},
+		{"HTML5 legacy compat", `<!DOCTYPE html SYSTEM "about:legacy-compat"><html></html>`, "HTML5", ""},
+		{"HTML 4.01 Strict", `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd"><html></html>`, "HTML 4.01 Strict", "-//W3C//DTD HTML 4.01//EN"},
+		{"HTML 4.01 Transitional", `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN" "http://www.w3.org/TR/html4/loose.dtd"><html></html>`, "HTML 4.01 Transitional", "-//W3C//DTD HTML 4.01 Transitional//EN"},
+		{"HTML 4.01 Frameset", `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Frameset//EN" "http://www.w3.org/TR/html4/frameset.dtd"><html></html>`, "HTML 4.01 Frameset", "-//W3C//DTD HTML 4.01 Frameset//EN"},
+		{"XHTML 1.0 Strict", `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Strict//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-strict.dtd"><html></html>`, "XHTML 1.0 Strict", "-//W3C//DTD XHTML 1.0 Strict//EN"},
+		{"XHTML 1.1", `<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.1//EN" "http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd"><html></html>`, "XHTML 1.1", "-//W3C//DTD XHTML 1.1//EN"},
+		{"HTML 3.2", `<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 3.2 Final//EN"><html></html>`, "HTML 3.2", "-//W3C//DTD HTML 3.2 Final//EN"},
+		{"No DOCTYPE", `<html><head><title>Old</title></head></html>`, htmlVersionQuirks, ""},
+	}
+
+	service := &crawlerService{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader(tt.html))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+
+			version, publicID, _ := service.extractHTMLVersion(doc)
+			if version != tt.version {
+				t.Errorf("Expected version '%s', got '%s'", tt.version, version)
+			}
+			if publicID != tt.publicID {
+				t.Errorf("Expected public ID '%s', got '%s'", tt.publicID, publicID)
+			}
+		})
+	}
+}
+
+func TestAnalyzeLinks_RecordsEveryLink(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	html := `<html><body>
+		<a href="/about">  About
+			us </a>
+		<a href="/about">About again</a>
+		<a href="/missing" rel="nofollow UGC" target="_blank">Gone</a>
+		<a href="https://partner.example/"><img src="/p.png" alt="Partner"></a>
+		<a href="mailto:hi@example.com">Mail</a>
+		<a href="tel:+4912345">Call</a>
+		<a href="javascript:void(0)" aria-label="Menu"></a>
+		<a href="#top">Top</a>
+	</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	service := NewCrawlerService(nil, nil, nil, nil, createTestConfig()).(*crawlerService)
+	base, _ := url.Parse(server.URL + "/")
+	analysis := service.analyzeLinks(doc, base.String(), newSiteScope(base, models.ScopeHost, nil), robotsPolicy{})
+
+	if analysis.InternalCount != 2 || analysis.ExternalCount != 1 {
+		t.Errorf("Expected 2 internal and 1 external distinct links, got %d and %d", analysis.InternalCount, analysis.ExternalCount)
+	}
+
+	expected := []struct {
+		url      string
+		text     string
+		scheme   string
+		internal bool
+		status   string
+	}{
+		{server.URL + "/about", "About us", "http", true, models.LinkOK},
+		{server.URL + "/about", "About again", "http", true, models.LinkOK},
+		{server.URL + "/missing", "Gone", "http", true, models.LinkBroken},
+		{"https://partner.example/", "Partner", "https", false, models.LinkBroken},
+		{"mailto:hi@example.com", "Mail", models.LinkSchemeMailto, false, models.LinkUnchecked},
+		{"tel:+4912345", "Call", models.LinkSchemeTel, false, models.LinkUnchecked},
+		{"javascript:void(0)", "Menu", models.LinkSchemeJavaScript, false, models.LinkUnchecked},
+		{server.URL + "/#top", "Top", models.LinkSchemeFragment, true, models.LinkUnchecked},
+	}
+	if len(analysis.Links) != len(expected) {
+		t.Fatalf("Expected %d links, got %d", len(expected), len(analysis.Links))
+	}
+	for i, want := range expected {
+		link := analysis.Links[i]
+		if link.Position != i+1 || link.URL != want.url || link.AnchorText != want.text || link.Scheme != want.scheme ||
+			link.Internal != want.internal || link.CheckStatus != want.status {
+			t.Errorf("Link %d: expected %+v, got %+v", i+1, want, link)
+		}
+	}
+
+	missing := analysis.Links[2]
+	if !missing.Nofollow || !missing.UGC || missing.Sponsored || missing.Rel != "nofollow ugc" || missing.Target != "_blank" || missing.StatusCode != http.StatusNotFound {
+		t.Errorf("Unexpected attributes for the broken link: %+v", missing)
+	}
+}
+
+func TestAnalyzeLinks_TruncatesTargetAndScheme(t *testing.T) {
+	html := `<a href="` + strings.Repeat("x", 40) + `:payload" target="` + strings.Repeat("w", 100) + `">Odd</a>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	service := NewCrawlerService(nil, nil, nil, nil, createTestConfig()).(*crawlerService)
+	base, _ := url.Parse("https://example.com/")
+	analysis := service.analyzeLinks(doc, base.String(), newSiteScope(base, models.ScopeHost, nil), robotsPolicy{})
+
+	if len(analysis.Links) != 1 {
+		t.Fatalf("Expected 1 link, got %d", len(analysis.Links))
+	}
+	link := analysis.Links[0]
+	if len(link.Target) != maxLinkTargetLength || len(link.Scheme) != maxLinkSchemeLength {
+		t.Errorf("Expected the target and scheme to fit their columns, got %d and %d characters", len(link.Target), len(link.Scheme))
+	}
+}
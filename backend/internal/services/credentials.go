@@ -0,0 +1,71 @@
+package services
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+
+	"sykell-crawler/pkg/config"
+)
+
+var errMalformedCiphertext = errors.New("malformed ciphertext")
+
+// credentialCipher encrypts the credentials stored with crawl profiles, their
+// passwords and cookie values, using AES-256-GCM. The key is derived from
+// CREDENTIALS_KEY, or from JWT_SECRET when that is not set, so changing the
+// secret makes stored credentials unreadable.
+type credentialCipher struct {
+	aead cipher.AEAD
+}
+
+func newCredentialCipher(cfg *config.Config) *credentialCipher {
+	secret := ""
+	if cfg != nil {
+		secret = cfg.CredentialsKey
+		if secret == "" {
+			secret = cfg.JWTSecret
+		}
+	}
+	key := sha256.Sum256([]byte("crawl-profile-credentials:" + secret))
+
+	// Neither call can fail for a 32-byte key
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		panic(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return &credentialCipher{aead: aead}
+}
+
+// encrypt returns the base64-encoded nonce and ciphertext of plaintext.
+func (c *credentialCipher) encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (c *credentialCipher) decrypt(encoded string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", errMalformedCiphertext, err)
+	}
+	if len(sealed) < c.aead.NonceSize() {
+		return "", errMalformedCiphertext
+	}
+	nonce, ciphertext := sealed[:c.aead.NonceSize()], sealed[c.aead.NonceSize():]
+	plaintext, err := c.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
@@ -0,0 +1,220 @@
+package services
+
+import (
+	"math/bits"
+	"sort"
+
+	"sykell-crawler/internal/repositories"
+)
+
+// Fields URLs can share in a duplicate cluster.
+const (
+	DuplicateTitle           = "title"
+	DuplicateMetaDescription = "meta_description"
+	DuplicateContent         = "content"
+)
+
+// Kinds of duplicate cluster. An exact cluster shares a title, description
+// or visible text; a near cluster has content fingerprints that differ in at
+// most the threshold number of bits.
+const (
+	DuplicateExact = "exact"
+	DuplicateNear  = "near"
+)
+
+const (
+	// DefaultNearDuplicateThreshold is the number of bits content fingerprints
+	// may differ in for the pages to count as near duplicates.
+	DefaultNearDuplicateThreshold = 3
+	// MaxNearDuplicateThreshold keeps clusters from merging unrelated pages.
+	MaxNearDuplicateThreshold = 16
+)
+
+// DuplicateOptions narrows the clusters returned by GetDuplicates. Empty
+// Field and Kind match all clusters.
+type DuplicateOptions struct {
+	Field     string
+	Kind      string
+	Threshold int
+}
+
+// DuplicateURL is a member of a duplicate cluster.
+type DuplicateURL struct {
+	ID    uint   `json:"id"`
+	URL   string `json:"url"`
+	Title string `json:"title"`
+}
+
+// DuplicateCluster is a group of URLs whose latest crawls share a field.
+// Value is the shared title or meta description. MaxDistance is the largest
+// number of bits two fingerprints in a near cluster differ in.
+type DuplicateCluster struct {
+	Field       string         `json:"field"`
+	Kind        string         `json:"kind"`
+	Value       string         `json:"value,omitempty"`
+	MaxDistance int            `json:"max_distance,omitempty"`
+	URLs        []DuplicateURL `json:"urls"`
+}
+
+// findDuplicates groups fingerprints into exact clusters for each field and
+// into near clusters of content fingerprints within threshold bits of each
+// other. Near clusters are transitive: A and C share a cluster when both are
+// close to B, even if they are not close to each other.
+func findDuplicates(fingerprints []*repositories.URLFingerprint, opts DuplicateOptions) []DuplicateCluster {
+	var clusters []DuplicateCluster
+	wants := func(field, kind string) bool {
+		return (opts.Field == "" || opts.Field == field) && (opts.Kind == "" || opts.Kind == kind)
+	}
+
+	if wants(DuplicateTitle, DuplicateExact) {
+		clusters = append(clusters, exactDuplicates(fingerprints, DuplicateTitle, func(fp *repositories.URLFingerprint) (string, string) {
+			return fp.TitleHash, fp.Title
+		})...)
+	}
+	if wants(DuplicateMetaDescription, DuplicateExact) {
+		clusters = append(clusters, exactDuplicates(fingerprints, DuplicateMetaDescription, func(fp *repositories.URLFingerprint) (string, string) {
+			return fp.MetaDescriptionHash, fp.MetaDescription
+		})...)
+	}
+	if wants(DuplicateContent, DuplicateExact) {
+		clusters = append(clusters, exactDuplicates(fingerprints, DuplicateContent, func(fp *repositories.URLFingerprint) (string, string) {
+			return fp.ContentHash, ""
+		})...)
+	}
+	if wants(DuplicateContent, DuplicateNear) && opts.Threshold > 0 {
+		clusters = append(clusters, nearDuplicates(fingerprints, opts.Threshold)...)
+	}
+
+	// Largest clusters first, as those are the most worth fixing
+	sort.SliceStable(clusters, func(i, j int) bool {
+		if len(clusters[i].URLs) != len(clusters[j].URLs) {
+			return len(clusters[i].URLs) > len(clusters[j].URLs)
+		}
+		return clusters[i].URLs[0].ID < clusters[j].URLs[0].ID
+	})
+	return clusters
+}
+
+// exactDuplicates groups fingerprints by the hash key returns, skipping
+// empty hashes and hashes only one URL has.
+func exactDuplicates(fingerprints []*repositories.URLFingerprint, field string, key func(*repositories.URLFingerprint) (hash, value string)) []DuplicateCluster {
+	groups := make(map[string]*DuplicateCluster)
+	var order []string
+	for _, fp := range fingerprints {
+		hash, value := key(fp)
+		if hash == "" {
+			continue
+		}
+		cluster, ok := groups[hash]
+		if !ok {
+			cluster = &DuplicateCluster{Field: field, Kind: DuplicateExact, Value: value}
+			groups[hash] = cluster
+			order = append(order, hash)
+		}
+		cluster.URLs = append(cluster.URLs, duplicateURL(fp))
+	}
+
+	var clusters []DuplicateCluster
+	for _, hash := range order {
+		if len(groups[hash].URLs) > 1 {
+			clusters = append(clusters, *groups[hash])
+		}
+	}
+	return clusters
+}
+
+// nearDuplicates links every pair of distinct content fingerprints within
+// threshold bits and returns the connected groups of more than one URL,
+// leaving out groups whose URLs all have the same text. Comparing every pair is quadratic in the number of distinct
+// fingerprints, so each is parsed once up front and pairs are compared with a
+// single XOR and popcount.
+func nearDuplicates(fingerprints []*repositories.URLFingerprint, threshold int) []DuplicateCluster {
+	var hashes []string
+	var values []uint64
+	members := make(map[string][]*repositories.URLFingerprint)
+	for _, fp := range fingerprints {
+		if _, ok := members[fp.ContentSimHash]; !ok {
+			value, valid := parseSimHash(fp.ContentSimHash)
+			if !valid {
+				continue
+			}
+			hashes = append(hashes, fp.ContentSimHash)
+			values = append(values, value)
+		}
+		members[fp.ContentSimHash] = append(members[fp.ContentSimHash], fp)
+	}
+	distance := func(i, j int) int {
+		return bits.OnesCount64(values[i] ^ values[j])
+	}
+
+	parent := make([]int, len(hashes))
+	for i := range parent {
+		parent[i] = i
+	}
+	var find func(int) int
+	find = func(i int) int {
+		if parent[i] != i {
+			parent[i] = find(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range hashes {
+		for j := i + 1; j < len(hashes); j++ {
+			if distance(i, j) <= threshold {
+				parent[find(j)] = find(i)
+			}
+		}
+	}
+
+	groups := make(map[int][]int)
+	var roots []int
+	for i := range hashes {
+		root := find(i)
+		if _, ok := groups[root]; !ok {
+			roots = append(roots, root)
+		}
+		groups[root] = append(groups[root], i)
+	}
+
+	var clusters []DuplicateCluster
+	for _, root := range roots {
+		group := groups[root]
+		if len(group) < 2 && sameContent(members[hashes[group[0]]]) {
+			// Identical text is already an exact cluster
+			continue
+		}
+		cluster := DuplicateCluster{Field: DuplicateContent, Kind: DuplicateNear}
+		for a, i := range group {
+			for _, fp := range members[hashes[i]] {
+				cluster.URLs = append(cluster.URLs, duplicateURL(fp))
+			}
+			for _, j := range group[a+1:] {
+				if d := distance(i, j); d > cluster.MaxDistance {
+					cluster.MaxDistance = d
+				}
+			}
+		}
+		sort.Slice(cluster.URLs, func(i, j int) bool { return cluster.URLs[i].ID < cluster.URLs[j].ID })
+		clusters = append(clusters, cluster)
+	}
+	return clusters
+}
+
+// sameContent reports whether fingerprints are a single URL or URLs with the
+// same text. Different text can still give the same SimHash, and such URLs
+// are near duplicates with a distance of 0.
+func sameContent(fingerprints []*repositories.URLFingerprint) bool {
+	if len(fingerprints) < 2 {
+		return true
+	}
+	for _, fp := range fingerprints[1:] {
+		if fp.ContentHash == "" || fp.ContentHash != fingerprints[0].ContentHash {
+			return false
+		}
+	}
+	return true
+}
+
+func duplicateURL(fp *repositories.URLFingerprint) DuplicateURL {
+	return DuplicateURL{ID: fp.URLID, URL: fp.URL, Title: fp.Title}
+}
@@ -0,0 +1,108 @@
+package services
+
+import (
+	"math/bits"
+	"strings"
+	"testing"
+
+	"sykell-crawler/internal/repositories"
+)
+
+const productCopy = "These running shoes have a breathable mesh upper, a cushioned midsole and a durable rubber outsole " +
+	"that grips on wet and dry roads. They are light enough for race day and comfortable enough for long training runs. " +
+	"Free returns within thirty days."
+
+func TestContentSimHash(t *testing.T) {
+	original := contentSimHash(textWords(productCopy))
+	if len(original) != 16 {
+		t.Fatalf("Expected a 16 digit fingerprint, got %q", original)
+	}
+	if again := contentSimHash(textWords(productCopy)); again != original {
+		t.Errorf("Expected the same text to give the same fingerprint, got %s and %s", original, again)
+	}
+
+	edited := contentSimHash(textWords(strings.Replace(productCopy, "thirty", "sixty", 1)))
+	if distance := simHashDistance(t, original, edited); distance > DefaultNearDuplicateThreshold*2 {
+		t.Errorf("Expected a one word edit to change few bits, got distance %d", distance)
+	}
+
+	unrelated := contentSimHash(textWords("Our privacy policy explains which personal data we collect when you visit " +
+		"the shop, why we collect it, how long it is kept and which rights you have to access or delete it at any time."))
+	if distance := simHashDistance(t, original, unrelated); distance <= MaxNearDuplicateThreshold {
+		t.Errorf("Expected unrelated text to differ in many bits, got distance %d", distance)
+	}
+
+	if short := contentSimHash(textWords("Out of stock")); short != "" {
+		t.Errorf("Expected no fingerprint for short text, got %q", short)
+	}
+}
+
+// simHashDistance returns the number of bits two fingerprints differ in.
+func simHashDistance(t *testing.T, a, b string) int {
+	t.Helper()
+	x, ok := parseSimHash(a)
+	if !ok {
+		t.Fatalf("Expected a valid fingerprint, got %q", a)
+	}
+	y, ok := parseSimHash(b)
+	if !ok {
+		t.Fatalf("Expected a valid fingerprint, got %q", b)
+	}
+	return bits.OnesCount64(x ^ y)
+}
+
+func TestTextHash(t *testing.T) {
+	if textHash("  Red   Shoes ") != textHash("red shoes") {
+		t.Error("Expected case and whitespace to be ignored")
+	}
+	if textHash("Red Shoes") == textHash("Blue Shoes") {
+		t.Error("Expected different titles to hash differently")
+	}
+	if textHash(" ") != "" {
+		t.Error("Expected no hash for an empty title")
+	}
+}
+
+func TestFindDuplicates(t *testing.T) {
+	fingerprints := []*repositories.URLFingerprint{
+		{URLID: 1, URL: "https://shop.example/a", Title: "Red Shoes", TitleHash: textHash("Red Shoes"), ContentSimHash: "00000000000000ff", ContentHash: textHash("red shoes")},
+		{URLID: 2, URL: "https://shop.example/b", Title: "red shoes", TitleHash: textHash("red shoes"), ContentSimHash: "00000000000000ff", ContentHash: textHash("red shoes")},
+		{URLID: 3, URL: "https://shop.example/c", Title: "Blue Shoes", TitleHash: textHash("Blue Shoes"), ContentSimHash: "00000000000000fe", ContentHash: textHash("blue shoes")},
+		{URLID: 4, URL: "https://shop.example/d", Title: "Green Shoes", TitleHash: textHash("Green Shoes"), ContentSimHash: "00000000000000fc", ContentHash: textHash("green shoes")},
+		{URLID: 5, URL: "https://shop.example/e", Title: "Socks", TitleHash: textHash("Socks"), ContentSimHash: "ffffffffffffff00", ContentHash: textHash("socks")},
+		{URLID: 6, URL: "https://shop.example/f", Title: "Hats", TitleHash: textHash("Hats")},
+		// Same SimHash as each other but different text
+		{URLID: 7, URL: "https://shop.example/g", Title: "Scarves", TitleHash: textHash("Scarves"), ContentSimHash: "0f00000000000000", ContentHash: textHash("wool scarves")},
+		{URLID: 8, URL: "https://shop.example/h", Title: "Gloves", TitleHash: textHash("Gloves"), ContentSimHash: "0f00000000000000", ContentHash: textHash("wool gloves")},
+	}
+
+	clusters := findDuplicates(fingerprints, DuplicateOptions{Threshold: 1})
+	if len(clusters) != 4 {
+		t.Fatalf("Expected 4 clusters, got %+v", clusters)
+	}
+
+	// The near cluster links 4 to 1 and 2 through 3
+	near := clusters[0]
+	if near.Kind != DuplicateNear || near.Field != DuplicateContent || len(near.URLs) != 4 || near.MaxDistance != 2 {
+		t.Errorf("Expected a near content cluster of 4 URLs with max distance 2, got %+v", near)
+	}
+	for _, cluster := range clusters[1:3] {
+		if cluster.Kind != DuplicateExact || len(cluster.URLs) != 2 || cluster.URLs[0].ID != 1 || cluster.URLs[1].ID != 2 {
+			t.Errorf("Expected an exact cluster of URLs 1 and 2, got %+v", cluster)
+		}
+	}
+	// Equal SimHashes of different text are near, not exact
+	if same := clusters[3]; same.Kind != DuplicateNear || len(same.URLs) != 2 || same.URLs[0].ID != 7 || same.MaxDistance != 0 {
+		t.Errorf("Expected a near cluster of URLs 7 and 8, got %+v", same)
+	}
+
+	titles := findDuplicates(fingerprints, DuplicateOptions{Field: DuplicateTitle, Threshold: 1})
+	if len(titles) != 1 || titles[0].Field != DuplicateTitle || titles[0].Value != "Red Shoes" {
+		t.Errorf("Expected only the duplicate title cluster, got %+v", titles)
+	}
+
+	exact := findDuplicates(fingerprints, DuplicateOptions{Kind: DuplicateExact, Threshold: 1})
+	if len(exact) != 2 {
+		t.Errorf("Expected only the exact clusters, got %+v", exact)
+	}
+}
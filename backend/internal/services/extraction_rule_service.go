@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/andybalholm/cascadia"
+	"gorm.io/gorm"
+	"sykell-crawler/internal/models"
+	"sykell-crawler/internal/repositories"
+)
+
+var (
+	// ErrRuleNotFound is returned when an extraction rule does not exist.
+	ErrRuleNotFound = errors.New("extraction rule not found")
+	// ErrInvalidRule is returned when an extraction rule fails validation.
+	ErrInvalidRule = errors.New("invalid extraction rule")
+)
+
+// ExtractionRuleInput describes an extraction rule to create or replace.
+// Exactly one of URLID and Group must be set.
+type ExtractionRuleInput struct {
+	Name      string
+	Selector  string
+	Mode      string
+	Attribute string
+	URLID     *uint
+	Group     string
+}
+
+type ExtractionRuleService interface {
+	CreateRule(input ExtractionRuleInput) (*models.ExtractionRule, error)
+	ListRules(urlID uint, group string) ([]*models.ExtractionRule, error)
+	UpdateRule(id uint, input ExtractionRuleInput) (*models.ExtractionRule, error)
+	DeleteRule(id uint) error
+}
+
+type extractionRuleService struct {
+	ruleRepo repositories.ExtractionRuleRepository
+	urlRepo  repositories.URLRepository
+}
+
+func NewExtractionRuleService(ruleRepo repositories.ExtractionRuleRepository, urlRepo repositories.URLRepository) ExtractionRuleService {
+	return &extractionRuleService{
+		ruleRepo: ruleRepo,
+		urlRepo:  urlRepo,
+	}
+}
+
+func (s *extractionRuleService) CreateRule(input ExtractionRuleInput) (*models.ExtractionRule, error) {
+	rule := &models.ExtractionRule{}
+	if err := s.apply(rule, input); err != nil {
+		return nil, err
+	}
+	if err := s.ruleRepo.Create(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+// ListRules lists the rules of a URL or a group, or all rules when both are
+// empty.
+func (s *extractionRuleService) ListRules(urlID uint, group string) ([]*models.ExtractionRule, error) {
+	return s.ruleRepo.GetAll(urlID, strings.TrimSpace(group))
+}
+
+// UpdateRule replaces a rule. Values already extracted by earlier crawls are
+// kept under the rule's old definition.
+func (s *extractionRuleService) UpdateRule(id uint, input ExtractionRuleInput) (*models.ExtractionRule, error) {
+	rule, err := s.ruleRepo.GetByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrRuleNotFound
+		}
+		return nil, err
+	}
+	if err := s.apply(rule, input); err != nil {
+		return nil, err
+	}
+	if err := s.ruleRepo.Update(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *extractionRuleService) DeleteRule(id uint) error {
+	if _, err := s.ruleRepo.GetByID(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRuleNotFound
+		}
+		return err
+	}
+	return s.ruleRepo.Delete(id)
+}
+
+// apply validates input and copies it onto rule.
+func (s *extractionRuleService) apply(rule *models.ExtractionRule, input ExtractionRuleInput) error {
+	name := strings.TrimSpace(input.Name)
+	selector := strings.TrimSpace(input.Selector)
+	group := strings.TrimSpace(input.Group)
+
+	if name == "" {
+		return fmt.Errorf("%w: name is required", ErrInvalidRule)
+	}
+	if _, err := cascadia.ParseGroup(selector); err != nil {
+		return fmt.Errorf("%w: selector %q: %v", ErrInvalidRule, selector, err)
+	}
+
+	attribute := ""
+	switch input.Mode {
+	case models.ExtractText, models.ExtractCount:
+	case models.ExtractAttribute:
+		attribute = strings.TrimSpace(input.Attribute)
+		if attribute == "" {
+			return fmt.Errorf("%w: attribute is required for attribute rules", ErrInvalidRule)
+		}
+	default:
+		return fmt.Errorf("%w: mode must be text, attribute or count", ErrInvalidRule)
+	}
+
+	if (input.URLID == nil) == (group == "") {
+		return fmt.Errorf("%w: exactly one of url_id and group is required", ErrInvalidRule)
+	}
+	if input.URLID != nil {
+		urls, err := s.urlRepo.GetByIDs([]uint{*input.URLID})
+		if err != nil {
+			return err
+		}
+		if len(urls) == 0 {
+			return fmt.Errorf("%w: URL %d does not exist", ErrInvalidRule, *input.URLID)
+		}
+	}
+
+	rule.Name = name
+	rule.Selector = selector
+	rule.Mode = input.Mode
+	rule.Attribute = attribute
+	rule.URLID = input.URLID
+	rule.Group = group
+	return nil
+}
@@ -0,0 +1,85 @@
+package services
+
+import (
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+)
+
+func init() {
+	RegisterAnalyzer(extractionRuleAnalyzer{})
+}
+
+// extractionRuleAnalyzer fills the extracted values of the crawl result.
+type extractionRuleAnalyzer struct{}
+
+func (extractionRuleAnalyzer) Name() string {
+	return "extraction-rules"
+}
+
+func (extractionRuleAnalyzer) Description() string {
+	return "Extracts values from pages with the extraction rules that apply to them"
+}
+
+func (extractionRuleAnalyzer) Analyze(input *AnalyzerInput) ([]models.Finding, error) {
+	input.Result.ExtractedValues = extractValues(input.Document, input.crawler.extractionRules(input.URL))
+	return nil, nil
+}
+
+// maxExtractedValueLength caps the text kept from a matched element, so a
+// selector that matches a whole section does not store the entire page.
+const maxExtractedValueLength = 1000
+
+// extractValues evaluates each rule against the page. A rule whose selector
+// matches nothing still yields a value with Matches 0, so the absence of an
+// element, such as a stock badge, is recorded too.
+func extractValues(doc *goquery.Document, rules []*models.ExtractionRule) []models.ExtractedValue {
+	var values []models.ExtractedValue
+	for _, rule := range rules {
+		matches := doc.Find(rule.Selector)
+		value := models.ExtractedValue{
+			RuleID:  rule.ID,
+			Name:    rule.Name,
+			Mode:    rule.Mode,
+			Matches: matches.Length(),
+		}
+
+		switch rule.Mode {
+		case models.ExtractText:
+			if matches.Length() > 0 {
+				value.Value = truncateRunes(strings.Join(strings.Fields(matches.First().Text()), " "), maxExtractedValueLength)
+			}
+		case models.ExtractAttribute:
+			// The first match that has the attribute, as with <meta> tags
+			// that only some matches carry content on
+			matches.EachWithBreak(func(_ int, element *goquery.Selection) bool {
+				attribute, ok := element.Attr(rule.Attribute)
+				if ok {
+					value.Value = truncateRunes(strings.TrimSpace(attribute), maxExtractedValueLength)
+				}
+				return !ok
+			})
+		case models.ExtractCount:
+			value.Value = strconv.Itoa(matches.Length())
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// extractionRules loads the rules that apply to a URL. Rules are optional, so
+// a failure to load them is logged and the page is crawled without them.
+func (s *crawlerService) extractionRules(urlModel *models.URL) []*models.ExtractionRule {
+	if s.ruleRepo == nil {
+		return nil
+	}
+	rules, err := s.ruleRepo.GetForURL(urlModel)
+	if err != nil {
+		log.Printf("Failed to load extraction rules for URL %d: %v", urlModel.ID, err)
+		return nil
+	}
+	return rules
+}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"gorm.io/gorm"
+	"sykell-crawler/internal/models"
+)
+
+type mockExtractionRuleRepository struct {
+	rules map[uint]*models.ExtractionRule
+}
+
+func (m *mockExtractionRuleRepository) Create(rule *models.ExtractionRule) error {
+	rule.ID = uint(len(m.rules) + 1)
+	m.rules[rule.ID] = rule
+	return nil
+}
+
+func (m *mockExtractionRuleRepository) GetByID(id uint) (*models.ExtractionRule, error) {
+	if rule, exists := m.rules[id]; exists {
+		return rule, nil
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (m *mockExtractionRuleRepository) GetAll(urlID uint, group string) ([]*models.ExtractionRule, error) {
+	return nil, nil
+}
+
+func (m *mockExtractionRuleRepository) GetForURL(url *models.URL) ([]*models.ExtractionRule, error) {
+	return nil, nil
+}
+
+func (m *mockExtractionRuleRepository) Update(rule *models.ExtractionRule) error {
+	m.rules[rule.ID] = rule
+	return nil
+}
+
+func (m *mockExtractionRuleRepository) Delete(id uint) error {
+	delete(m.rules, id)
+	return nil
+}
+
+func TestExtractValues(t *testing.T) {
+	html := `<html><head><meta property="product:price:amount" content=" 49.90 "></head><body>
+<h1 class="product-title">  Trail   Runner 2 </h1>
+<span class="badge">In stock</span><span class="badge">Free shipping</span>
+<img class="gallery" src="/a.jpg"><img class="gallery" data-src="/b.jpg"><img class="gallery" data-src="/c.jpg">
+</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	rules := []*models.ExtractionRule{
+		{ID: 1, Name: "title", Selector: "h1.product-title", Mode: models.ExtractText},
+		{ID: 2, Name: "price", Selector: `meta[property="product:price:amount"]`, Mode: models.ExtractAttribute, Attribute: "content"},
+		{ID: 3, Name: "lazy image", Selector: "img.gallery", Mode: models.ExtractAttribute, Attribute: "data-src"},
+		{ID: 4, Name: "badges", Selector: ".badge", Mode: models.ExtractCount},
+		{ID: 5, Name: "cookie banner", Selector: "#cookie-banner", Mode: models.ExtractText},
+	}
+	values := extractValues(doc, rules)
+
+	expected := []struct {
+		value   string
+		matches int
+	}{
+		{"Trail Runner 2", 1},
+		{"49.90", 1},
+		{"/b.jpg", 3},
+		{"2", 2},
+		{"", 0},
+	}
+	if len(values) != len(expected) {
+		t.Fatalf("Expected %d values, got %+v", len(expected), values)
+	}
+	for i, want := range expected {
+		got := values[i]
+		if got.RuleID != rules[i].ID || got.Name != rules[i].Name || got.Value != want.value || got.Matches != want.matches {
+			t.Errorf("Rule %q: expected value %q with %d matches, got %+v", rules[i].Name, want.value, want.matches, got)
+		}
+	}
+}
+
+func TestExtractionRuleService_Validation(t *testing.T) {
+	ruleRepo := &mockExtractionRuleRepository{rules: make(map[uint]*models.ExtractionRule)}
+	urlRepo := &mockURLRepository{urls: map[uint]*models.URL{1: {ID: 1, URL: "https://shop.example.com"}}}
+	service := NewExtractionRuleService(ruleRepo, urlRepo)
+
+	urlID, missingID := uint(1), uint(99)
+	invalid := map[string]ExtractionRuleInput{
+		"bad selector":      {Name: "price", Selector: "span[", Mode: models.ExtractText, URLID: &urlID},
+		"unknown mode":      {Name: "price", Selector: ".price", Mode: "html", URLID: &urlID},
+		"missing attribute": {Name: "price", Selector: ".price", Mode: models.ExtractAttribute, URLID: &urlID},
+		"no target":         {Name: "price", Selector: ".price", Mode: models.ExtractText},
+		"both targets":      {Name: "price", Selector: ".price", Mode: models.ExtractText, URLID: &urlID, Group: "shoes"},
+		"missing URL":       {Name: "price", Selector: ".price", Mode: models.ExtractText, URLID: &missingID},
+		"blank name":        {Name: "  ", Selector: ".price", Mode: models.ExtractText, Group: "shoes"},
+	}
+	for name, input := range invalid {
+		if _, err := service.CreateRule(input); !errors.Is(err, ErrInvalidRule) {
+			t.Errorf("%s: expected ErrInvalidRule, got %v", name, err)
+		}
+	}
+
+	rule, err := service.CreateRule(ExtractionRuleInput{Name: " price ", Selector: ".price", Mode: models.ExtractText, Attribute: "ignored", Group: " shoes "})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if rule.Name != "price" || rule.Group != "shoes" || rule.Attribute != "" {
+		t.Errorf("Expected the rule to be normalized, got %+v", rule)
+	}
+
+	if _, err := service.UpdateRule(42, ExtractionRuleInput{Name: "price", Selector: ".price", Mode: models.ExtractCount, Group: "shoes"}); !errors.Is(err, ErrRuleNotFound) {
+		t.Errorf("Expected ErrRuleNotFound, got %v", err)
+	}
+}
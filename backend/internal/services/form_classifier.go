@@ -0,0 +1,317 @@
+package services
+
+import (
+	"math"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"golang.org/x/net/html"
+	"sykell-crawler/internal/models"
+)
+
+func init() {
+	RegisterAnalyzer(formAnalyzer{})
+}
+
+// formAnalyzer fills the forms of the crawl result and whether the page has
+// a login form.
+type formAnalyzer struct{}
+
+func (formAnalyzer) Name() string {
+	return "forms"
+}
+
+func (formAnalyzer) Description() string {
+	return "Detects login, signup and password reset forms"
+}
+
+func (formAnalyzer) Analyze(input *AnalyzerInput) ([]models.Finding, error) {
+	result := input.Result
+	result.Forms = classifyForms(input.Document, input.PageURL)
+	for _, form := range result.Forms {
+		if form.Kind == models.FormLogin {
+			result.HasLoginForm = true
+		}
+	}
+	return nil, nil
+}
+
+// minFormConfidence is the score a form needs before it is reported as a
+// login, signup or password reset form. A password field next to a username
+// field is just enough on its own.
+const minFormConfidence = 0.35
+
+// formKeywords are lower-case phrases that name each kind of form, in
+// English, German, French, Spanish, Italian, Portuguese, Dutch, Polish,
+// Russian, Chinese, Japanese and Korean.
+var formKeywords = map[string][]string{
+	models.FormLogin: {
+		"log in", "login", "log-in", "sign in", "signin", "sign-in", "remember me",
+		"anmelden", "einloggen", "angemeldet bleiben",
+		"connexion", "se connecter", "identifiant",
+		"iniciar sesión", "iniciar sesion", "acceder", "entrar",
+		"accedi", "accesso",
+		"inloggen",
+		"zaloguj", "logowanie",
+		"войти", "вход",
+		"登录", "登入", "ログイン", "로그인",
+	},
+	models.FormSignup: {
+		"sign up", "signup", "sign-up", "register", "registration", "create account", "create an account",
+		"registrieren", "registrierung", "konto erstellen",
+		"s'inscrire", "inscription", "créer un compte",
+		"registrarse", "regístrate", "registrate", "crear cuenta", "crear una cuenta",
+		"registrati", "crea account",
+		"cadastre", "cadastrar", "criar conta",
+		"registreren", "account aanmaken",
+		"zarejestruj", "rejestracja",
+		"регистрация", "зарегистрироваться",
+		"注册", "註冊", "会員登録", "회원가입",
+	},
+	models.FormPasswordReset: {
+		"forgot password", "forgot your password", "reset password", "reset your password", "password reset", "recover password", "reset link", "forgot-password", "reset-password",
+		"passwort vergessen", "passwort zurücksetzen",
+		"mot de passe oublié", "réinitialiser",
+		"olvidaste tu contraseña", "restablecer", "recuperar contraseña",
+		"password dimenticata", "reimposta",
+		"esqueceu", "redefinir senha", "recuperar senha",
+		"wachtwoord vergeten",
+		"nie pamiętasz hasła", "resetuj hasło",
+		"забыли пароль", "восстановить пароль",
+		"忘记密码", "重置密码", "パスワードを忘れ", "パスワードの再設定", "비밀번호 찾기",
+	},
+}
+
+// identityFieldHints mark inputs that take a username or email address.
+var identityFieldHints = []string{"user", "email", "mail", "login", "benutzer", "correo", "identifiant"}
+
+// personalFieldHints mark inputs that only a signup form asks for.
+var personalFieldHints = []string{"firstname", "first_name", "lastname", "last_name", "fullname", "full_name", "given-name", "family-name", "birthday", "bday"}
+
+// consentFieldHints mark the terms checkbox of a signup form.
+var consentFieldHints = []string{"terms", "tos", "agree", "consent", "agb"}
+
+// formFeatures are the signals a form is classified by.
+type formFeatures struct {
+	passwordFields  int
+	currentPassword bool
+	newPassword     bool
+	identityField   bool
+	personalField   bool
+	consentField    bool
+	textFields      int
+	submitText      string
+	descriptiveText string
+}
+
+// classifyForms finds the login, signup and password reset forms on a page.
+// Besides <form> elements it looks at password inputs outside any form, whose
+// nearest ancestor with a button is treated as the form. Each candidate is
+// scored for every kind from its fields, their autocomplete hints and the
+// words on its buttons, labels and attributes; the best kind is kept when it
+// reaches minFormConfidence.
+func classifyForms(doc *goquery.Document, pageURL string) []models.AuthForm {
+	var forms []models.AuthForm
+
+	classify := func(container *goquery.Selection, isForm bool) {
+		features := collectFormFeatures(container)
+		if features.passwordFields == 0 && !features.identityField {
+			return
+		}
+		kind, confidence := scoreForm(features)
+		if confidence < minFormConfidence {
+			return
+		}
+
+		form := models.AuthForm{
+			Position:   len(forms) + 1,
+			Kind:       kind,
+			Confidence: math.Round(confidence*100) / 100,
+			Fields:     formFieldNames(container),
+		}
+		if isForm {
+			form.Action = resolveURL(pageURL, container.AttrOr("action", ""))
+			form.Method = strings.ToUpper(strings.TrimSpace(container.AttrOr("method", "")))
+			if form.Method != "POST" {
+				form.Method = "GET"
+			}
+		}
+		forms = append(forms, form)
+	}
+
+	doc.Find("form").Each(func(_ int, form *goquery.Selection) {
+		classify(form, true)
+	})
+
+	seen := make(map[*html.Node]bool)
+	doc.Find("input").Each(func(_ int, input *goquery.Selection) {
+		if inputType(input) != "password" || input.Closest("form").Length() > 0 {
+			return
+		}
+		container := input.Parent()
+		for goquery.NodeName(container) != "body" && container.Parent().Length() > 0 && !hasSubmitControl(container) {
+			container = container.Parent()
+		}
+		if container.Length() == 0 || seen[container.Get(0)] {
+			return
+		}
+		seen[container.Get(0)] = true
+		classify(container, false)
+	})
+
+	return forms
+}
+
+// inputType returns the lower-case type of an input, defaulting to text.
+func inputType(input *goquery.Selection) string {
+	t := strings.ToLower(strings.TrimSpace(input.AttrOr("type", "")))
+	if t == "" {
+		return "text"
+	}
+	return t
+}
+
+func hasSubmitControl(container *goquery.Selection) bool {
+	return container.Find("button, [role='button']").Length() > 0 ||
+		container.Find("input").FilterFunction(func(_ int, input *goquery.Selection) bool {
+			t := inputType(input)
+			return t == "submit" || t == "button" || t == "image"
+		}).Length() > 0
+}
+
+func collectFormFeatures(container *goquery.Selection) formFeatures {
+	var features formFeatures
+	var submit, descriptive []string
+
+	container.Find("input, select, textarea").Each(func(_ int, field *goquery.Selection) {
+		t := inputType(field)
+		autocomplete := strings.ToLower(field.AttrOr("autocomplete", ""))
+		hints := strings.ToLower(field.AttrOr("name", "") + " " + field.AttrOr("id", "") + " " + autocomplete)
+
+		switch t {
+		case "hidden":
+			return
+		case "submit", "button", "image":
+			submit = append(submit, field.AttrOr("value", ""), field.AttrOr("alt", ""), field.AttrOr("aria-label", ""))
+			return
+		case "password":
+			features.passwordFields++
+			features.currentPassword = features.currentPassword || strings.Contains(autocomplete, "current-password")
+			features.newPassword = features.newPassword || strings.Contains(autocomplete, "new-password")
+		case "checkbox":
+			if containsAny(hints, consentFieldHints) {
+				features.consentField = true
+			}
+		default:
+			features.textFields++
+			if t == "email" || containsAny(hints, identityFieldHints) {
+				features.identityField = true
+			} else if containsAny(hints, personalFieldHints) {
+				features.personalField = true
+			}
+		}
+		descriptive = append(descriptive, field.AttrOr("placeholder", ""), field.AttrOr("aria-label", ""))
+	})
+
+	container.Find("button, [role='button']").Each(func(_ int, button *goquery.Selection) {
+		submit = append(submit, button.Text(), button.AttrOr("value", ""), button.AttrOr("aria-label", ""))
+	})
+	container.Find("label, legend, h1, h2, h3, h4, h5, h6").Each(func(_ int, text *goquery.Selection) {
+		descriptive = append(descriptive, text.Text())
+	})
+	descriptive = append(descriptive,
+		container.AttrOr("action", ""), container.AttrOr("id", ""),
+		container.AttrOr("class", ""), container.AttrOr("name", ""), container.AttrOr("aria-label", ""))
+
+	features.submitText = strings.ToLower(strings.Join(submit, " "))
+	features.descriptiveText = strings.ToLower(strings.Join(descriptive, " "))
+	return features
+}
+
+// scoreForm scores a form as each kind and returns the most likely one with
+// its score, capped at 1.
+func scoreForm(f formFeatures) (string, float64) {
+	scores := make(map[string]float64, len(formKeywords))
+	for kind, keywords := range formKeywords {
+		if containsAny(f.submitText, keywords) {
+			scores[kind] += 0.45
+		}
+		if containsAny(f.descriptiveText, keywords) {
+			scores[kind] += 0.2
+		}
+	}
+
+	switch {
+	case f.passwordFields == 1 && !f.newPassword:
+		scores[models.FormLogin] += 0.25
+		if f.identityField {
+			scores[models.FormLogin] += 0.1
+		}
+	case f.passwordFields > 1:
+		// A repeated password is confirmed, which login forms never ask for
+		scores[models.FormSignup] += 0.2
+		scores[models.FormPasswordReset] += 0.1
+	}
+	if f.currentPassword {
+		scores[models.FormLogin] += 0.3
+	}
+	if f.newPassword {
+		scores[models.FormSignup] += 0.3
+		scores[models.FormPasswordReset] += 0.1
+	}
+	if f.personalField {
+		scores[models.FormSignup] += 0.2
+	}
+	if f.consentField {
+		scores[models.FormSignup] += 0.15
+	}
+	if f.passwordFields == 0 && f.identityField && f.textFields == 1 {
+		// A lone email or username field is how a reset is requested
+		scores[models.FormPasswordReset] += 0.2
+	}
+	if f.passwordFields == 0 {
+		// Without a password field a login or signup wording is more likely a
+		// newsletter or magic link form
+		scores[models.FormLogin] /= 2
+		scores[models.FormSignup] /= 2
+	}
+
+	bestKind, bestScore := "", 0.0
+	for _, kind := range []string{models.FormLogin, models.FormSignup, models.FormPasswordReset} {
+		if scores[kind] > bestScore {
+			bestKind, bestScore = kind, scores[kind]
+		}
+	}
+	return bestKind, math.Min(bestScore, 1)
+}
+
+// formFieldNames lists the names of a form's fields, falling back to their
+// ids. Buttons are left out.
+func formFieldNames(container *goquery.Selection) []string {
+	var names []string
+	container.Find("input, select, textarea").Each(func(_ int, field *goquery.Selection) {
+		switch inputType(field) {
+		case "submit", "button", "image", "reset":
+			if goquery.NodeName(field) == "input" {
+				return
+			}
+		}
+		name := field.AttrOr("name", "")
+		if name == "" {
+			name = field.AttrOr("id", "")
+		}
+		if name != "" {
+			names = append(names, name)
+		}
+	})
+	return names
+}
+
+func containsAny(text string, phrases []string) bool {
+	for _, phrase := range phrases {
+		if strings.Contains(text, phrase) {
+			return true
+		}
+	}
+	return false
+}
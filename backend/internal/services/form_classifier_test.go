@@ -0,0 +1,128 @@
+package services
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+)
+
+func TestClassifyForms(t *testing.T) {
+	tests := []struct {
+		name   string
+		html   string
+		kind   string
+		action string
+		method string
+		fields []string
+	}{
+		{
+			name: "English login form",
+			html: `<form action="/session" method="post">
+				<input type="hidden" name="csrf" value="x">
+				<input type="email" name="email">
+				<input type="password" name="password" autocomplete="current-password">
+				<a href="/forgot">Forgot password?</a>
+				<button type="submit">Log in</button>
+			</form>`,
+			kind:   models.FormLogin,
+			action: "https://example.com/session",
+			method: "POST",
+			fields: []string{"csrf", "email", "password"},
+		},
+		{
+			name: "German login form",
+			html: `<form action="/anmelden" method="post">
+				<label for="user">Benutzername</label><input id="user" name="benutzer">
+				<input type="password" name="passwort">
+				<input type="submit" value="Anmelden">
+			</form>`,
+			kind:   models.FormLogin,
+			action: "https://example.com/anmelden",
+			method: "POST",
+			fields: []string{"benutzer", "passwort"},
+		},
+		{
+			name:   "Japanese login form without keywords in fields",
+			html:   `<form><input name="id"><input type="password" name="pw"><button>ログイン</button></form>`,
+			kind:   models.FormLogin,
+			action: "https://example.com/login",
+			method: "GET",
+			fields: []string{"id", "pw"},
+		},
+		{
+			name: "signup form",
+			html: `<form action="/users" method="post">
+				<input name="first_name"><input type="email" name="email">
+				<input type="password" name="password" autocomplete="new-password">
+				<input type="password" name="password_confirmation" autocomplete="new-password">
+				<input type="checkbox" name="accept_terms">
+				<button>Create account</button>
+			</form>`,
+			kind:   models.FormSignup,
+			action: "https://example.com/users",
+			method: "POST",
+			fields: []string{"first_name", "email", "password", "password_confirmation", "accept_terms"},
+		},
+		{
+			name: "Spanish password reset form",
+			html: `<form action="/recuperar" method="post">
+				<h2>¿Olvidaste tu contraseña?</h2>
+				<input type="email" name="correo">
+				<button>Enviar</button>
+			</form>`,
+			kind:   models.FormPasswordReset,
+			action: "https://example.com/recuperar",
+			method: "POST",
+			fields: []string{"correo"},
+		},
+		{
+			name:   "login widget without a form element",
+			html:   `<div class="auth"><div><input name="username"></div><div><input type="password" name="password"></div><button>Sign in</button></div>`,
+			kind:   models.FormLogin,
+			fields: []string{"username", "password"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc, err := goquery.NewDocumentFromReader(strings.NewReader("<html><body>" + tt.html + "</body></html>"))
+			if err != nil {
+				t.Fatalf("Failed to parse HTML: %v", err)
+			}
+
+			forms := classifyForms(doc, "https://example.com/login")
+
+			if len(forms) != 1 {
+				t.Fatalf("Expected 1 form, got %+v", forms)
+			}
+			form := forms[0]
+			if form.Kind != tt.kind {
+				t.Errorf("Expected kind %s, got %s (confidence %.2f)", tt.kind, form.Kind, form.Confidence)
+			}
+			if form.Action != tt.action || form.Method != tt.method {
+				t.Errorf("Expected %s %s, got %s %s", tt.method, tt.action, form.Method, form.Action)
+			}
+			if !reflect.DeepEqual(form.Fields, tt.fields) {
+				t.Errorf("Expected fields %v, got %v", tt.fields, form.Fields)
+			}
+		})
+	}
+}
+
+func TestClassifyForms_IgnoresOtherForms(t *testing.T) {
+	html := `<html><body>
+		<form action="/search"><input type="search" name="q"><button>Search</button></form>
+		<form action="/newsletter" method="post"><input type="email" name="email"><button>Sign up</button></form>
+	</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	if forms := classifyForms(doc, "https://example.com/"); len(forms) != 0 {
+		t.Errorf("Expected no forms, got %+v", forms)
+	}
+}
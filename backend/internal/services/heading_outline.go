@@ -0,0 +1,73 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+)
+
+// maxHeadingTextLength caps the stored text of a single heading in runes.
+const maxHeadingTextLength = 500
+
+// extractHeadingOutline returns the page's h1–h6 headings in document order
+// together with the problems in their hierarchy: a missing or repeated h1,
+// levels skipped on the way down (h2 followed by h4) and headings without
+// text. An image's alt text counts as heading text, as it does for screen
+// readers.
+func extractHeadingOutline(doc *goquery.Document) ([]models.Heading, []models.HeadingIssue) {
+	var headings []models.Heading
+	var issues []models.HeadingIssue
+	h1Count := 0
+	previousLevel := 0
+
+	doc.Find("h1, h2, h3, h4, h5, h6").Each(func(i int, heading *goquery.Selection) {
+		position := i + 1
+		level := int(goquery.NodeName(heading)[1] - '0')
+		text := headingText(heading)
+		headings = append(headings, models.Heading{Position: position, Level: level, Text: text})
+
+		if level == 1 {
+			h1Count++
+		}
+		if previousLevel > 0 && level > previousLevel+1 {
+			issues = append(issues, models.HeadingIssue{
+				Code:     models.HeadingSkippedLevel,
+				Position: position,
+				Message:  fmt.Sprintf("h%d follows h%d, skipping h%d", level, previousLevel, previousLevel+1),
+			})
+		}
+		if text == "" {
+			issues = append(issues, models.HeadingIssue{
+				Code:     models.HeadingEmpty,
+				Position: position,
+				Message:  fmt.Sprintf("h%d has no text", level),
+			})
+		}
+		previousLevel = level
+	})
+
+	switch {
+	case h1Count == 0:
+		issues = append(issues, models.HeadingIssue{Code: models.HeadingMissingH1, Message: "page has no h1"})
+	case h1Count > 1:
+		issues = append(issues, models.HeadingIssue{Code: models.HeadingMultipleH1, Message: fmt.Sprintf("page has %d h1 headings", h1Count)})
+	}
+
+	return headings, issues
+}
+
+func headingText(heading *goquery.Selection) string {
+	text := strings.Join(strings.Fields(heading.Text()), " ")
+	if text == "" {
+		var alts []string
+		heading.Find("img[alt]").Each(func(_ int, img *goquery.Selection) {
+			if alt := strings.TrimSpace(img.AttrOr("alt", "")); alt != "" {
+				alts = append(alts, alt)
+			}
+		})
+		text = strings.Join(alts, " ")
+	}
+	return truncateRunes(text, maxHeadingTextLength)
+}
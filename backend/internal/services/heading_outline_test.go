@@ -0,0 +1,74 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+)
+
+func TestExtractHeadingOutline(t *testing.T) {
+	html := `<html><body>
+<h1>Title</h1>
+<h2>  Section
+	one </h2>
+<h4>Too deep</h4>
+<h2><img src="/logo.png" alt="Logo"></h2>
+<h3></h3>
+<h1>Second title</h1>
+</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	headings, issues := extractHeadingOutline(doc)
+
+	expected := []models.Heading{
+		{Position: 1, Level: 1, Text: "Title"},
+		{Position: 2, Level: 2, Text: "Section one"},
+		{Position: 3, Level: 4, Text: "Too deep"},
+		{Position: 4, Level: 2, Text: "Logo"},
+		{Position: 5, Level: 3, Text: ""},
+		{Position: 6, Level: 1, Text: "Second title"},
+	}
+	if len(headings) != len(expected) {
+		t.Fatalf("Expected %d headings, got %d", len(expected), len(headings))
+	}
+	for i, want := range expected {
+		if headings[i] != want {
+			t.Errorf("Expected heading %+v, got %+v", want, headings[i])
+		}
+	}
+
+	expectedIssues := []struct {
+		code     string
+		position int
+	}{
+		{models.HeadingSkippedLevel, 3},
+		{models.HeadingEmpty, 5},
+		{models.HeadingMultipleH1, 0},
+	}
+	if len(issues) != len(expectedIssues) {
+		t.Fatalf("Expected %d issues, got %+v", len(expectedIssues), issues)
+	}
+	for i, want := range expectedIssues {
+		if issues[i].Code != want.code || issues[i].Position != want.position {
+			t.Errorf("Expected issue %s at %d, got %+v", want.code, want.position, issues[i])
+		}
+	}
+}
+
+func TestExtractHeadingOutline_MissingH1(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><body><h2>Only a subtitle</h2></body></html>`))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	_, issues := extractHeadingOutline(doc)
+
+	if len(issues) != 1 || issues[0].Code != models.HeadingMissingH1 {
+		t.Errorf("Expected a missing h1 issue, got %+v", issues)
+	}
+}
@@ -0,0 +1,184 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+	"unicode"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+)
+
+func init() {
+	RegisterAnalyzer(imageAnalyzer{})
+}
+
+// imageAnalyzer fills the image counts of the crawl result and adds the
+// images robots.txt disallows to its blocked URLs.
+type imageAnalyzer struct{}
+
+func (imageAnalyzer) Name() string {
+	return "images"
+}
+
+func (imageAnalyzer) Description() string {
+	return "Checks the images pages reference and counts images without alt text"
+}
+
+func (imageAnalyzer) Analyze(input *AnalyzerInput) ([]models.Finding, error) {
+	result := input.Result
+	images := input.crawler.analyzeImages(input.Document, input.PageURL, input.robots)
+	result.Images = len(images.Sources)
+	result.ImagesMissingAlt = images.MissingAlt
+	result.BrokenImages = len(images.BrokenImages)
+	result.BrokenImageURLs = images.BrokenImages
+	result.BlockedImages = len(images.BlockedImages)
+	for _, source := range images.BlockedImages {
+		result.BlockedURLs = append(result.BlockedURLs, models.BlockedURL{Kind: models.BlockedImage, URL: source})
+	}
+	return nil, nil
+}
+
+// imageAnalysis summarizes the images referenced by a page.
+type imageAnalysis struct {
+	Sources       []string
+	MissingAlt    int
+	BrokenImages  []models.BrokenImage
+	BlockedImages []string
+}
+
+// analyzeImages collects every image source referenced through <img src>,
+// srcset candidates and <picture> sources, counts <img> elements without an
+// alt attribute and checks each source with the link-check client unless the
+// crawl profile turns image checks off. Sources robots.txt disallows are
+// listed as blocked instead of being checked. An empty alt is a valid way to
+// mark an image as decorative and is not counted.
+func (s *crawlerService) analyzeImages(doc *goquery.Document, baseURL string, robots robotsPolicy) imageAnalysis {
+	var analysis imageAnalysis
+
+	analysis.MissingAlt = doc.Find("img").FilterFunction(func(_ int, img *goquery.Selection) bool {
+		_, hasAlt := img.Attr("alt")
+		return !hasAlt
+	}).Length()
+
+	analysis.Sources = collectImageSources(doc, baseURL)
+
+	var toCheck []string
+	for _, source := range analysis.Sources {
+		switch {
+		case !robots.allowed(source):
+			analysis.BlockedImages = append(analysis.BlockedImages, source)
+		case !s.skipImageChecks():
+			toCheck = append(toCheck, source)
+		}
+	}
+
+	for _, status := range s.checkURLs(toCheck, robots) {
+		if status.Err != nil || status.StatusCode >= 400 {
+			brokenImage := models.BrokenImage{
+				URL:        status.URL,
+				StatusCode: status.StatusCode,
+			}
+			if status.Err != nil {
+				brokenImage.ErrorMessage = status.Err.Error()
+			}
+			analysis.BrokenImages = append(analysis.BrokenImages, brokenImage)
+		}
+	}
+
+	return analysis
+}
+
+// collectImageSources returns the unique absolute http(s) image URLs on the
+// page in document order, including images preloaded with
+// <link rel=preload as=image>. Inline data: images are skipped.
+func collectImageSources(doc *goquery.Document, baseURL string) []string {
+	parsedBase, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+
+	var sources []string
+	seen := make(map[string]bool)
+	add := func(ref string) {
+		source, ok := resolveSubresourceURL(parsedBase, ref)
+		if !ok || seen[source] {
+			return
+		}
+		seen[source] = true
+		sources = append(sources, source)
+	}
+
+	doc.Find("img, picture source, link").Each(func(_ int, image *goquery.Selection) {
+		switch goquery.NodeName(image) {
+		case "img":
+			add(image.AttrOr("src", ""))
+		case "link":
+			if !hasRel(image, "preload") || !strings.EqualFold(strings.TrimSpace(image.AttrOr("as", "")), "image") {
+				return
+			}
+			add(image.AttrOr("href", ""))
+			for _, candidate := range parseSrcset(image.AttrOr("imagesrcset", "")) {
+				add(candidate)
+			}
+			return
+		}
+		for _, candidate := range parseSrcset(image.AttrOr("srcset", "")) {
+			add(candidate)
+		}
+	})
+
+	return sources
+}
+
+// parseSrcset returns the URLs of the image candidates in a srcset attribute.
+// Candidates are separated by commas, but URLs may contain commas themselves,
+// so a URL only ends at whitespace or at trailing commas.
+func parseSrcset(srcset string) []string {
+	var urls []string
+	rest := srcset
+	for {
+		rest = strings.TrimLeftFunc(rest, func(r rune) bool {
+			return r == ',' || unicode.IsSpace(r)
+		})
+		if rest == "" {
+			return urls
+		}
+
+		end := strings.IndexFunc(rest, unicode.IsSpace)
+		if end < 0 {
+			end = len(rest)
+		}
+		candidate := rest[:end]
+		rest = rest[end:]
+
+		if trimmed := strings.TrimRight(candidate, ","); trimmed != candidate {
+			// The candidate had no descriptors
+			urls = append(urls, trimmed)
+			continue
+		}
+		urls = append(urls, candidate)
+		rest = skipSrcsetDescriptors(rest)
+	}
+}
+
+// skipSrcsetDescriptors drops the descriptors of a srcset candidate, which end
+// at the next comma outside parentheses.
+func skipSrcsetDescriptors(rest string) string {
+	depth := 0
+	for i := 0; i < len(rest); i++ {
+		switch rest[i] {
+		case '(':
+			depth++
+		case ')':
+			if depth > 0 {
+				depth--
+			}
+		case ',':
+			if depth == 0 {
+				return rest[i:]
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+)
+
+func TestParseSrcset(t *testing.T) {
+	tests := []struct {
+		name   string
+		srcset string
+		want   []string
+	}{
+		{"Single URL", "a.png", []string{"a.png"}},
+		{"Width descriptors", "a.png 480w, b.png 800w", []string{"a.png", "b.png"}},
+		{"Density descriptors without spaces", "a.png 1x,b.png 2x", []string{"a.png", "b.png"}},
+		{"No descriptors", "a.png, b.png", []string{"a.png", "b.png"}},
+		{"Comma inside URL", "/img/w_200,h_100/a.png 1x, /img/w_400,h_200/a.png 2x", []string{"/img/w_200,h_100/a.png", "/img/w_400,h_200/a.png"}},
+		{"Empty", "  ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := parseSrcset(tt.srcset)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAnalyzeImages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasPrefix(r.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	html := `<html><head>
+<link rel="preload" href="/preloaded.jpg" as="image">
+<link rel="preload" href="/font.woff2" as="font">
+</head><body>
+<img src="/logo.png" alt="Logo">
+<img src="/missing.png">
+<img src="/spacer.gif" alt="">
+<img src="data:image/gif;base64,R0lGODlhAQABAAAAACw=" srcset="/small.png 1x, /missing-2x.png 2x">
+<picture>
+	<source srcset="/hero.webp" type="image/webp">
+	<img src="/logo.png" alt="Hero">
+</picture>
+</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	service := NewCrawlerService(nil, nil, nil, nil, createTestConfig()).(*crawlerService)
+	analysis := service.analyzeImages(doc, server.URL+"/page", robotsPolicy{})
+
+	expectedSources := []string{
+		server.URL + "/preloaded.jpg",
+		server.URL + "/logo.png",
+		server.URL + "/missing.png",
+		server.URL + "/spacer.gif",
+		server.URL + "/small.png",
+		server.URL + "/missing-2x.png",
+		server.URL + "/hero.webp",
+	}
+	if !reflect.DeepEqual(analysis.Sources, expectedSources) {
+		t.Errorf("Expected sources %v, got %v", expectedSources, analysis.Sources)
+	}
+	if analysis.MissingAlt != 2 {
+		t.Errorf("Expected 2 images without alt, got %d", analysis.MissingAlt)
+	}
+	if len(analysis.BrokenImages) != 2 {
+		t.Fatalf("Expected 2 broken images, got %d", len(analysis.BrokenImages))
+	}
+	if analysis.BrokenImages[0].URL != server.URL+"/missing.png" || analysis.BrokenImages[0].StatusCode != http.StatusNotFound {
+		t.Errorf("Unexpected broken image %+v", analysis.BrokenImages[0])
+	}
+}
+
+func TestAnalyzeImages_RecordsBlockedImages(t *testing.T) {
+	var checked []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nDisallow: /private/\n"))
+			return
+		}
+		checked = append(checked, r.URL.Path)
+	}))
+	defer server.Close()
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<img src="/logo.png" alt=""><img src="/private/badge.png" alt="">`))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	service := NewCrawlerService(nil, nil, nil, nil, createTestConfig()).(*crawlerService)
+	analysis := service.analyzeImages(doc, server.URL+"/page", service.robotsPolicyFor(&models.URL{URL: server.URL}))
+
+	if !reflect.DeepEqual(analysis.BlockedImages, []string{server.URL + "/private/badge.png"}) {
+		t.Errorf("Expected the disallowed image to be listed as blocked, got %v", analysis.BlockedImages)
+	}
+	if !reflect.DeepEqual(checked, []string{"/logo.png"}) {
+		t.Errorf("Expected only the allowed image to be checked, got %v", checked)
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"net/url"
+	"sync"
+)
+
+// linkStatus is the outcome of checking a single link.
+type linkStatus struct {
+	URL        string
+	StatusCode int
+	Err        error
+}
+
+// checkURLs checks every URL concurrently while keeping at most
+// LinkCheckConcurrency requests in flight overall and LinkCheckPerHost
+// requests in flight per host. Statuses are returned in input order.
+func (s *crawlerService) checkURLs(targets []string, robots robotsPolicy) []linkStatus {
+	statuses := make([]linkStatus, len(targets))
+	if len(targets) == 0 {
+		return statuses
+	}
+
+	global := make(chan struct{}, s.linkCheckConcurrency())
+	hostSlots := make(map[string]chan struct{})
+	for _, target := range targets {
+		host := linkHost(target)
+		if _, exists := hostSlots[host]; !exists {
+			hostSlots[host] = make(chan struct{}, s.linkCheckPerHost())
+		}
+	}
+
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target string) {
+			defer wg.Done()
+
+			// Take the host slot first so a busy host can't hold global slots hostage
+			hostSlot := hostSlots[linkHost(target)]
+			hostSlot <- struct{}{}
+			defer func() { <-hostSlot }()
+
+			robots.wait(target)
+
+			global <- struct{}{}
+			defer func() { <-global }()
+
+			statusCode, err := s.checkURL(target)
+			statuses[i] = linkStatus{URL: target, StatusCode: statusCode, Err: err}
+		}(i, target)
+	}
+	wg.Wait()
+
+	return statuses
+}
+
+func (s *crawlerService) linkCheckConcurrency() int {
+	if s.config == nil || s.config.LinkCheckConcurrency < 1 {
+		return 1
+	}
+	return s.config.LinkCheckConcurrency
+}
+
+func (s *crawlerService) linkCheckPerHost() int {
+	if s.config == nil || s.config.LinkCheckPerHost < 1 {
+		return 1
+	}
+	return s.config.LinkCheckPerHost
+}
+
+func linkHost(target string) string {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return ""
+	}
+	return parsed.Host
+}
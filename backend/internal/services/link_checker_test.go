@@ -0,0 +1,81 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sykell-crawler/pkg/config"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCheckURLs_PreservesOrder(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/slow":
+			time.Sleep(50 * time.Millisecond)
+			w.WriteHeader(http.StatusOK)
+		case "/missing":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer server.Close()
+
+	cfg := createTestConfig()
+	cfg.LinkCheckConcurrency = 4
+	cfg.LinkCheckPerHost = 4
+	service := NewCrawlerService(nil, nil, nil, nil, cfg).(*crawlerService)
+
+	targets := []string{server.URL + "/slow", server.URL + "/missing", server.URL + "/ok"}
+	statuses := service.checkURLs(targets, robotsPolicy{})
+
+	if len(statuses) != len(targets) {
+		t.Fatalf("Expected %d statuses, got %d", len(targets), len(statuses))
+	}
+
+	expectedCodes := []int{http.StatusOK, http.StatusNotFound, http.StatusOK}
+	for i, status := range statuses {
+		if status.URL != targets[i] {
+			t.Errorf("Expected status %d to be for '%s', got '%s'", i, targets[i], status.URL)
+		}
+		if status.StatusCode != expectedCodes[i] {
+			t.Errorf("Expected status code %d for '%s', got %d", expectedCodes[i], status.URL, status.StatusCode)
+		}
+	}
+}
+
+func TestCheckURLs_RespectsPerHostLimit(t *testing.T) {
+	var inFlight, maxInFlight int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		for {
+			max := atomic.LoadInt32(&maxInFlight)
+			if current <= max || atomic.CompareAndSwapInt32(&maxInFlight, max, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		atomic.AddInt32(&inFlight, -1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cfg := &config.Config{
+		LinkCheckTimeout:     5 * time.Second,
+		LinkCheckConcurrency: 10,
+		LinkCheckPerHost:     2,
+	}
+	service := NewCrawlerService(nil, nil, nil, nil, cfg).(*crawlerService)
+
+	var targets []string
+	for i := 0; i < 8; i++ {
+		targets = append(targets, server.URL+"/page"+string(rune('a'+i)))
+	}
+	service.checkURLs(targets, robotsPolicy{})
+
+	if maxInFlight > 2 {
+		t.Errorf("Expected at most 2 concurrent requests per host, got %d", maxInFlight)
+	}
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+)
+
+func init() {
+	RegisterAnalyzer(metaRefreshAnalyzer{})
+}
+
+// metaRefreshAnalyzer reports <meta http-equiv="refresh"> tags. A refresh
+// that points elsewhere is a client-side redirect, which search engines may
+// not follow like an HTTP redirect and which a delay makes visible to users.
+type metaRefreshAnalyzer struct{}
+
+func (metaRefreshAnalyzer) Name() string {
+	return "meta-refresh"
+}
+
+func (metaRefreshAnalyzer) Description() string {
+	return "Reports pages that reload or redirect with a meta refresh tag"
+}
+
+func (metaRefreshAnalyzer) Analyze(input *AnalyzerInput) ([]models.Finding, error) {
+	var findings []models.Finding
+	input.Document.Find("meta[http-equiv]").Each(func(_ int, meta *goquery.Selection) {
+		if !strings.EqualFold(strings.TrimSpace(meta.AttrOr("http-equiv", "")), "refresh") {
+			return
+		}
+		delay, target := parseMetaRefresh(meta.AttrOr("content", ""))
+		data := map[string]interface{}{"delay": delay}
+
+		if target == "" {
+			findings = append(findings, models.Finding{
+				Type:     "reload",
+				Severity: models.FindingInfo,
+				Message:  fmt.Sprintf("page reloads itself every %d seconds", delay),
+				Data:     data,
+			})
+			return
+		}
+
+		target = resolveURL(input.PageURL, target)
+		data["url"] = target
+		findings = append(findings, models.Finding{
+			Type:     "redirect",
+			Severity: models.FindingWarning,
+			Message:  fmt.Sprintf("page redirects to %s after %d seconds; use an HTTP redirect instead", target, delay),
+			Data:     data,
+		})
+	})
+	return findings, nil
+}
+
+// parseMetaRefresh splits a refresh value such as `5; url='/next'` into its
+// delay in seconds and target URL, following the lenient parsing browsers use.
+func parseMetaRefresh(content string) (int, string) {
+	content = strings.TrimSpace(content)
+	end := 0
+	for end < len(content) && content[end] >= '0' && content[end] <= '9' {
+		end++
+	}
+	delay, _ := strconv.Atoi(content[:end])
+
+	rest := strings.TrimLeft(content[end:], "0123456789.")
+	rest = strings.TrimLeft(rest, " \t\n\r;,")
+	if len(rest) >= 3 && strings.EqualFold(rest[:3], "url") {
+		if value := strings.TrimLeft(rest[3:], " \t\n\r"); strings.HasPrefix(value, "=") {
+			rest = strings.TrimSpace(value[1:])
+		}
+	}
+	return delay, strings.Trim(rest, `'"`)
+}
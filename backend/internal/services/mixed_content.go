@@ -0,0 +1,146 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+)
+
+func init() {
+	RegisterAnalyzer(mixedContentAnalyzer{})
+}
+
+// mixedContentAnalyzer fills the mixed content of the crawl result.
+type mixedContentAnalyzer struct{}
+
+func (mixedContentAnalyzer) Name() string {
+	return "mixed-content"
+}
+
+func (mixedContentAnalyzer) Description() string {
+	return "Lists the http:// URLs HTTPS pages load or submit forms to"
+}
+
+func (mixedContentAnalyzer) Analyze(input *AnalyzerInput) ([]models.Finding, error) {
+	result := input.Result
+	result.MixedContent = findMixedContent(input.Document, input.PageURL)
+	for _, mixed := range result.MixedContent {
+		if mixed.Kind == models.MixedContentActive {
+			result.ActiveMixedContent++
+		} else {
+			result.PassiveMixedContent++
+		}
+	}
+	return nil, nil
+}
+
+// mixedContentAttribute is an attribute that makes the browser load or
+// submit to a URL, and the kind of mixed content it is when that URL is
+// http://.
+type mixedContentAttribute struct {
+	name string
+	kind string
+}
+
+// mixedContentAttributes lists the URL attributes of each element. Media and
+// images are passive; anything that runs code, frames content or receives
+// form data is active. <link> depends on its rel and is handled separately.
+var mixedContentAttributes = map[string][]mixedContentAttribute{
+	"script": {{"src", models.MixedContentActive}},
+	"iframe": {{"src", models.MixedContentActive}},
+	"frame":  {{"src", models.MixedContentActive}},
+	"embed":  {{"src", models.MixedContentActive}},
+	"object": {{"data", models.MixedContentActive}},
+	"track":  {{"src", models.MixedContentActive}},
+	"form":   {{"action", models.MixedContentActive}},
+	"button": {{"formaction", models.MixedContentActive}},
+	"input":  {{"src", models.MixedContentPassive}, {"formaction", models.MixedContentActive}},
+	"img":    {{"src", models.MixedContentPassive}, {"srcset", models.MixedContentPassive}},
+	"source": {{"src", models.MixedContentPassive}, {"srcset", models.MixedContentPassive}},
+	"video":  {{"src", models.MixedContentPassive}, {"poster", models.MixedContentPassive}},
+	"audio":  {{"src", models.MixedContentPassive}},
+}
+
+const mixedContentSelector = "script, iframe, frame, embed, object, track, form, button, input, img, source, video, audio, link[href]"
+
+// findMixedContent lists the http:// URLs an HTTPS page loads or submits
+// forms to, in document order. Relative references count too when a <base>
+// element points them at an http:// URL. Pages served over plain HTTP have no
+// mixed content.
+func findMixedContent(doc *goquery.Document, pageURL string) []models.MixedContent {
+	base, err := url.Parse(pageURL)
+	if err != nil || base.Scheme != "https" {
+		return nil
+	}
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if ref, err := url.Parse(strings.TrimSpace(href)); err == nil {
+			base = base.ResolveReference(ref)
+		}
+	}
+
+	var found []models.MixedContent
+	add := func(element, attribute, kind, ref string) {
+		target, ok := resolveSubresourceURL(base, ref)
+		if !ok || !strings.HasPrefix(target, "http://") {
+			return
+		}
+		found = append(found, models.MixedContent{
+			Position:  len(found) + 1,
+			Kind:      kind,
+			Element:   element,
+			Attribute: attribute,
+			URL:       target,
+		})
+	}
+
+	doc.Find(mixedContentSelector).Each(func(_ int, element *goquery.Selection) {
+		name := goquery.NodeName(element)
+		if name == "link" {
+			if kind := linkMixedContentKind(element); kind != "" {
+				add(name, "href", kind, element.AttrOr("href", ""))
+			}
+			return
+		}
+
+		for _, attribute := range mixedContentAttributes[name] {
+			value, ok := element.Attr(attribute.name)
+			if !ok {
+				continue
+			}
+			// Only image inputs load their src
+			if name == "input" && attribute.name == "src" && !strings.EqualFold(element.AttrOr("type", ""), "image") {
+				continue
+			}
+			if attribute.name == "srcset" {
+				for _, candidate := range parseSrcset(value) {
+					add(name, attribute.name, attribute.kind, candidate)
+				}
+				continue
+			}
+			add(name, attribute.name, attribute.kind, value)
+		}
+	})
+
+	return found
+}
+
+// linkMixedContentKind classifies a <link> by what it loads, or returns ""
+// for relations such as canonical that the browser does not fetch.
+func linkMixedContentKind(link *goquery.Selection) string {
+	if hasRel(link, "preload") {
+		switch strings.ToLower(strings.TrimSpace(link.AttrOr("as", ""))) {
+		case "image", "audio", "video":
+			return models.MixedContentPassive
+		}
+		return models.MixedContentActive
+	}
+	switch linkResourceKind(link) {
+	case "":
+		return ""
+	case models.ResourceIcon:
+		return models.MixedContentPassive
+	}
+	return models.MixedContentActive
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+)
+
+func TestFindMixedContent(t *testing.T) {
+	html := `<html><head>
+<link rel="stylesheet" href="http://cdn.example.com/style.css">
+<link rel="icon" href="http://cdn.example.com/favicon.ico">
+<link rel="preload" href="http://cdn.example.com/hero.jpg" as="image">
+<link rel="canonical" href="http://example.com/page">
+<script src="http://cdn.example.com/app.js"></script>
+<script src="https://cdn.example.com/safe.js"></script>
+</head><body>
+<a href="http://example.com/old">Plain links are not mixed content</a>
+<img src="/logo.png" srcset="http://cdn.example.com/logo-2x.png 2x">
+<video poster="http://cdn.example.com/poster.jpg"><source src="http://cdn.example.com/intro.mp4"></video>
+<iframe src="http://widgets.example.com/embed"></iframe>
+<form action="http://example.com/login"><input type="text" src="http://example.com/ignored.png"><button formaction="http://example.com/alt">Go</button></form>
+</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	found := findMixedContent(doc, "https://example.com/page")
+
+	expected := []struct {
+		kind, element, attribute, url string
+	}{
+		{models.MixedContentActive, "link", "href", "http://cdn.example.com/style.css"},
+		{models.MixedContentPassive, "link", "href", "http://cdn.example.com/favicon.ico"},
+		{models.MixedContentPassive, "link", "href", "http://cdn.example.com/hero.jpg"},
+		{models.MixedContentActive, "script", "src", "http://cdn.example.com/app.js"},
+		{models.MixedContentPassive, "img", "srcset", "http://cdn.example.com/logo-2x.png"},
+		{models.MixedContentPassive, "video", "poster", "http://cdn.example.com/poster.jpg"},
+		{models.MixedContentPassive, "source", "src", "http://cdn.example.com/intro.mp4"},
+		{models.MixedContentActive, "iframe", "src", "http://widgets.example.com/embed"},
+		{models.MixedContentActive, "form", "action", "http://example.com/login"},
+		{models.MixedContentActive, "button", "formaction", "http://example.com/alt"},
+	}
+	if len(found) != len(expected) {
+		t.Fatalf("Expected %d mixed content references, got %d: %+v", len(expected), len(found), found)
+	}
+	for i, want := range expected {
+		got := found[i]
+		if got.Position != i+1 || got.Kind != want.kind || got.Element != want.element || got.Attribute != want.attribute || got.URL != want.url {
+			t.Errorf("Reference %d: expected %+v, got %+v", i+1, want, got)
+		}
+	}
+}
+
+func TestFindMixedContent_BaseAndPlainHTTP(t *testing.T) {
+	html := `<html><head><base href="http://static.example.com/"></head><body><img src="logo.png"></body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	found := findMixedContent(doc, "https://example.com/")
+	if len(found) != 1 || found[0].URL != "http://static.example.com/logo.png" {
+		t.Errorf("Expected the relative image to resolve against the http base, got %+v", found)
+	}
+
+	if found := findMixedContent(doc, "http://example.com/"); len(found) != 0 {
+		t.Errorf("Expected no mixed content on an http page, got %+v", found)
+	}
+}
@@ -0,0 +1,177 @@
+package services
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"net/url"
+	"time"
+
+	"sykell-crawler/internal/models"
+)
+
+var (
+	errRedirectLoop     = errors.New("redirect loop")
+	errTooManyRedirects = errors.New("too many redirects")
+)
+
+// defaultMaxRedirects is used when the configuration does not set a limit.
+const defaultMaxRedirects = 10
+
+// pageFetch is the response to a page request after following redirects.
+type pageFetch struct {
+	Response  *http.Response
+	FinalURL  string
+	Redirects []models.RedirectHop
+	TLS       *models.TLSInfo
+	Timing    *requestTiming
+}
+
+// fetchError keeps what was learned about a fetch before it failed, such as
+// the redirects followed, so it can still be reported.
+type fetchError struct {
+	err   error
+	fetch *pageFetch
+}
+
+func (e *fetchError) Error() string {
+	return e.err.Error()
+}
+
+func (e *fetchError) Unwrap() error {
+	return e.err
+}
+
+// withFetch attaches the partial results of a fetch to err.
+func withFetch(err error, fetch *pageFetch) error {
+	if fetch == nil || (len(fetch.Redirects) == 0 && fetch.TLS == nil) {
+		return err
+	}
+	return &fetchError{err: err, fetch: fetch}
+}
+
+// fetchPage requests targetURL and follows redirects itself so every hop can
+// be recorded. Each hop is checked against robots.txt like the first request.
+// A chain that revisits a URL or exceeds the configured limit is reported as
+// an error instead of being followed further.
+func (s *crawlerService) fetchPage(targetURL string, robots robotsPolicy) (*pageFetch, error) {
+	fetch := &pageFetch{FinalURL: targetURL}
+	visited := map[string]bool{targetURL: true}
+
+	for {
+		if !robots.allowed(fetch.FinalURL) {
+			return nil, withFetch(errBlockedByRobots, fetch)
+		}
+		robots.wait(fetch.FinalURL)
+
+		timing := newRequestTiming()
+		req, err := http.NewRequestWithContext(httptrace.WithClientTrace(context.Background(), timing.trace()), http.MethodGet, fetch.FinalURL, nil)
+		if err != nil {
+			return nil, withFetch(fmt.Errorf("failed to fetch URL: %w", err), fetch)
+		}
+		// Asking for gzip explicitly stops the transport from decompressing
+		// transparently, so meterBody can count the compressed size
+		req.Header.Set("Accept-Encoding", "gzip")
+
+		resp, err := s.client.Do(req)
+		if err != nil {
+			var certErr *tls.CertificateVerificationError
+			if errors.As(err, &certErr) {
+				// The certificate is what failed, so it is worth reporting
+				fetch.TLS = s.probeTLS(fetch.FinalURL, certErr)
+			}
+			return nil, withFetch(fmt.Errorf("failed to fetch URL: %w", err), fetch)
+		}
+
+		location := resp.Header.Get("Location")
+		if !isRedirectStatus(resp.StatusCode) || location == "" {
+			if err := meterBody(resp, timing); err != nil {
+				return nil, withFetch(err, fetch)
+			}
+			fetch.Response = resp
+			fetch.TLS = s.inspectTLS(fetch.FinalURL, resp.TLS)
+			fetch.Timing = timing
+			return fetch, nil
+		}
+
+		// Drain a little of the body so the connection can be reused
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+		resp.Body.Close()
+
+		fetch.Redirects = append(fetch.Redirects, models.RedirectHop{
+			Position:   len(fetch.Redirects) + 1,
+			URL:        fetch.FinalURL,
+			StatusCode: resp.StatusCode,
+			Location:   location,
+			DurationMs: time.Since(timing.start).Milliseconds(),
+		})
+
+		next, err := resolveRedirect(fetch.FinalURL, location)
+		if err != nil {
+			return nil, withFetch(err, fetch)
+		}
+		if visited[next] {
+			return nil, withFetch(fmt.Errorf("%w: %s redirects back to %s", errRedirectLoop, fetch.FinalURL, next), fetch)
+		}
+		if len(fetch.Redirects) >= s.maxRedirects() {
+			return nil, withFetch(fmt.Errorf("%w: stopped after %d redirects", errTooManyRedirects, len(fetch.Redirects)), fetch)
+		}
+
+		visited[next] = true
+		fetch.FinalURL = next
+	}
+}
+
+func (s *crawlerService) maxRedirects() int {
+	if s.config == nil || s.config.MaxRedirects < 1 {
+		return defaultMaxRedirects
+	}
+	return s.config.MaxRedirects
+}
+
+func isRedirectStatus(code int) bool {
+	switch code {
+	case http.StatusMovedPermanently, http.StatusFound, http.StatusSeeOther,
+		http.StatusTemporaryRedirect, http.StatusPermanentRedirect:
+		return true
+	}
+	return false
+}
+
+func resolveRedirect(current, location string) (string, error) {
+	base, err := url.Parse(current)
+	if err != nil {
+		return "", fmt.Errorf("invalid redirect source %q: %w", current, err)
+	}
+	ref, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("invalid redirect location %q: %w", location, err)
+	}
+	next := base.ResolveReference(ref)
+	if next.Scheme != "http" && next.Scheme != "https" {
+		return "", fmt.Errorf("unsupported redirect location %q", location)
+	}
+	return next.String(), nil
+}
+
+// failedResult builds the crawl result recorded for a page that could not be
+// crawled, keeping any redirects followed before the failure.
+func failedResult(err error) *models.CrawlResult {
+	result := &models.CrawlResult{
+		ErrorMessage:    err.Error(),
+		BlockedByRobots: errors.Is(err, errBlockedByRobots),
+	}
+
+	var fetchErr *fetchError
+	if errors.As(err, &fetchErr) {
+		result.FinalURL = fetchErr.fetch.FinalURL
+		result.RedirectCount = len(fetchErr.fetch.Redirects)
+		result.Redirects = fetchErr.fetch.Redirects
+		setTLSInfo(result, fetchErr.fetch.TLS)
+	}
+	return result
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newRedirectTestServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/start", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/middle", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/middle", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/final", http.StatusFound)
+	})
+	mux.HandleFunc("/final", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html><head><title>Final</title></head></html>`))
+	})
+	mux.HandleFunc("/loop-a", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop-b", http.StatusFound)
+	})
+	mux.HandleFunc("/loop-b", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop-a", http.StatusFound)
+	})
+	mux.HandleFunc("/chain/", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, r.URL.Path+"x", http.StatusTemporaryRedirect)
+	})
+	return httptest.NewServer(mux)
+}
+
+func TestFetchPage_RecordsRedirectChain(t *testing.T) {
+	server := newRedirectTestServer()
+	defer server.Close()
+
+	service := NewCrawlerService(nil, nil, nil, nil, createTestConfig()).(*crawlerService)
+	fetch, err := service.fetchPage(server.URL+"/start", robotsPolicy{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer fetch.Response.Body.Close()
+
+	if fetch.FinalURL != server.URL+"/final" {
+		t.Errorf("Expected final URL '%s', got '%s'", server.URL+"/final", fetch.FinalURL)
+	}
+	if fetch.Response.StatusCode != http.StatusOK {
+		t.Errorf("Expected final status 200, got %d", fetch.Response.StatusCode)
+	}
+	if len(fetch.Redirects) != 2 {
+		t.Fatalf("Expected 2 redirects, got %d", len(fetch.Redirects))
+	}
+
+	first := fetch.Redirects[0]
+	if first.Position != 1 || first.URL != server.URL+"/start" || first.StatusCode != http.StatusMovedPermanently || first.Location != "/middle" {
+		t.Errorf("Unexpected first hop %+v", first)
+	}
+	second := fetch.Redirects[1]
+	if second.Position != 2 || second.URL != server.URL+"/middle" || second.StatusCode != http.StatusFound {
+		t.Errorf("Unexpected second hop %+v", second)
+	}
+}
+
+func TestFetchPage_DetectsRedirectLoop(t *testing.T) {
+	server := newRedirectTestServer()
+	defer server.Close()
+
+	service := NewCrawlerService(nil, nil, nil, nil, createTestConfig()).(*crawlerService)
+	_, err := service.fetchPage(server.URL+"/loop-a", robotsPolicy{})
+	if !errors.Is(err, errRedirectLoop) {
+		t.Fatalf("Expected a redirect loop error, got %v", err)
+	}
+
+	result := failedResult(err)
+	if result.RedirectCount != 2 || len(result.Redirects) != 2 {
+		t.Errorf("Expected the 2 hops of the loop to be kept, got %d", result.RedirectCount)
+	}
+	if result.FinalURL != server.URL+"/loop-b" {
+		t.Errorf("Expected final URL '%s', got '%s'", server.URL+"/loop-b", result.FinalURL)
+	}
+}
+
+func TestFetchPage_LimitsRedirectChain(t *testing.T) {
+	server := newRedirectTestServer()
+	defer server.Close()
+
+	cfg := createTestConfig()
+	cfg.MaxRedirects = 3
+	service := NewCrawlerService(nil, nil, nil, nil, cfg).(*crawlerService)
+	_, err := service.fetchPage(server.URL+"/chain/", robotsPolicy{})
+	if !errors.Is(err, errTooManyRedirects) {
+		t.Fatalf("Expected a too many redirects error, got %v", err)
+	}
+
+	if result := failedResult(err); result.RedirectCount != 3 {
+		t.Errorf("Expected 3 recorded hops, got %d", result.RedirectCount)
+	}
+}
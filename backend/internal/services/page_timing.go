@@ -0,0 +1,153 @@
+package services
+
+import (
+	"compress/gzip"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+
+	"sykell-crawler/internal/models"
+)
+
+// requestTiming records when each phase of a page request happened and how
+// many bytes its body took on the wire and once decompressed.
+type requestTiming struct {
+	mu                sync.Mutex
+	start             time.Time
+	dnsStart          time.Time
+	dnsDone           time.Time
+	connectStart      time.Time
+	connectDone       time.Time
+	tlsStart          time.Time
+	tlsDone           time.Time
+	firstByte         time.Time
+	done              time.Time
+	compressedBytes   int64
+	uncompressedBytes int64
+}
+
+func newRequestTiming() *requestTiming {
+	return &requestTiming{start: time.Now()}
+}
+
+// trace returns hooks that record the connection phases. Phases are skipped
+// when a kept-alive connection is reused, leaving their durations at zero.
+func (t *requestTiming) trace() *httptrace.ClientTrace {
+	record := func(at *time.Time) {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		// Dual-stack dialing can start several connects; keep the first
+		if at.IsZero() {
+			*at = time.Now()
+		}
+	}
+	return &httptrace.ClientTrace{
+		DNSStart:             func(httptrace.DNSStartInfo) { record(&t.dnsStart) },
+		DNSDone:              func(httptrace.DNSDoneInfo) { record(&t.dnsDone) },
+		ConnectStart:         func(string, string) { record(&t.connectStart) },
+		ConnectDone:          func(string, string, error) { record(&t.connectDone) },
+		TLSHandshakeStart:    func() { record(&t.tlsStart) },
+		TLSHandshakeDone:     func(tls.ConnectionState, error) { record(&t.tlsDone) },
+		GotFirstResponseByte: func() { record(&t.firstByte) },
+	}
+}
+
+// apply stores the recorded timings and sizes on a crawl result.
+func (t *requestTiming) apply(result *models.CrawlResult) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	result.DNSLookupMs = elapsedMs(t.dnsStart, t.dnsDone)
+	result.ConnectMs = elapsedMs(t.connectStart, t.connectDone)
+	result.TLSHandshakeMs = elapsedMs(t.tlsStart, t.tlsDone)
+	result.TTFBMs = elapsedMs(t.start, t.firstByte)
+	result.DownloadMs = elapsedMs(t.firstByte, t.done)
+	result.TotalMs = elapsedMs(t.start, t.done)
+	result.ResponseBytes = t.compressedBytes
+	result.UncompressedBytes = t.uncompressedBytes
+}
+
+func elapsedMs(from, to time.Time) int64 {
+	if from.IsZero() || to.IsZero() {
+		return 0
+	}
+	return to.Sub(from).Milliseconds()
+}
+
+// meterBody replaces the response body with one that decompresses gzip
+// itself, so both the wire size and the decoded size can be counted, and
+// that marks the download as done once it is fully read or closed.
+func meterBody(resp *http.Response, timing *requestTiming) error {
+	wire := &countingReader{reader: resp.Body}
+	body := &meteredBody{wire: wire, reader: wire, closer: resp.Body, timing: timing}
+
+	if strings.EqualFold(strings.TrimSpace(resp.Header.Get("Content-Encoding")), "gzip") {
+		gz, err := gzip.NewReader(wire)
+		switch {
+		case err == io.EOF:
+			// An empty body, such as that of a 204, has no gzip header
+		case err != nil:
+			resp.Body.Close()
+			return fmt.Errorf("failed to decode gzip body: %w", err)
+		default:
+			body.reader = gz
+		}
+		resp.Header.Del("Content-Encoding")
+		resp.Header.Del("Content-Length")
+		resp.ContentLength = -1
+		resp.Uncompressed = true
+	}
+
+	resp.Body = body
+	return nil
+}
+
+type countingReader struct {
+	reader io.Reader
+	n      int64
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += int64(n)
+	return n, err
+}
+
+type meteredBody struct {
+	wire   *countingReader
+	reader io.Reader
+	closer io.Closer
+	timing *requestTiming
+}
+
+func (b *meteredBody) Read(p []byte) (int, error) {
+	n, err := b.reader.Read(p)
+
+	b.timing.mu.Lock()
+	b.timing.uncompressedBytes += int64(n)
+	b.timing.mu.Unlock()
+
+	if err == io.EOF {
+		b.finish()
+	}
+	return n, err
+}
+
+func (b *meteredBody) Close() error {
+	b.finish()
+	return b.closer.Close()
+}
+
+func (b *meteredBody) finish() {
+	b.timing.mu.Lock()
+	defer b.timing.mu.Unlock()
+	if b.timing.done.IsZero() {
+		b.timing.done = time.Now()
+		b.timing.compressedBytes = b.wire.n
+	}
+}
@@ -0,0 +1,81 @@
+package services
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"sykell-crawler/internal/models"
+)
+
+func TestFetchPage_MeasuresTimingAndSize(t *testing.T) {
+	body := "<html><body>" + strings.Repeat("<p>repetitive content</p>", 200) + "</body></html>"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(20 * time.Millisecond)
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			w.Write([]byte(body))
+			return
+		}
+		w.Header().Set("Content-Encoding", "gzip")
+		gz := gzip.NewWriter(w)
+		gz.Write([]byte(body))
+		gz.Close()
+	}))
+	defer server.Close()
+
+	service := NewCrawlerService(nil, nil, nil, nil, createTestConfig()).(*crawlerService)
+	fetch, err := service.fetchPage(server.URL, robotsPolicy{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	decoded, err := io.ReadAll(fetch.Response.Body)
+	fetch.Response.Body.Close()
+	if err != nil {
+		t.Fatalf("Failed to read body: %v", err)
+	}
+	if string(decoded) != body {
+		t.Fatal("Expected the body to be decompressed")
+	}
+
+	result := &models.CrawlResult{}
+	fetch.Timing.apply(result)
+
+	if result.UncompressedBytes != int64(len(body)) {
+		t.Errorf("Expected %d uncompressed bytes, got %d", len(body), result.UncompressedBytes)
+	}
+	if result.ResponseBytes == 0 || result.ResponseBytes >= result.UncompressedBytes {
+		t.Errorf("Expected a smaller compressed size, got %d of %d", result.ResponseBytes, result.UncompressedBytes)
+	}
+	if result.TTFBMs < 20 {
+		t.Errorf("Expected TTFB to include the server delay, got %dms", result.TTFBMs)
+	}
+	if result.TotalMs < result.TTFBMs {
+		t.Errorf("Expected total time %dms to cover TTFB %dms", result.TotalMs, result.TTFBMs)
+	}
+}
+
+func TestFetchPage_EmptyGzipBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	service := NewCrawlerService(nil, nil, nil, nil, createTestConfig()).(*crawlerService)
+	fetch, err := service.fetchPage(server.URL, robotsPolicy{})
+	if err != nil {
+		t.Fatalf("Expected the empty body not to fail the fetch, got %v", err)
+	}
+	defer fetch.Response.Body.Close()
+
+	if fetch.Response.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected the real status, got %d", fetch.Response.StatusCode)
+	}
+	if decoded, err := io.ReadAll(fetch.Response.Body); err != nil || len(decoded) != 0 {
+		t.Errorf("Expected an empty body, got %q, %v", decoded, err)
+	}
+}
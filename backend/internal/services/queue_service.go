@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"sykell-crawler/internal/models"
 	"time"
 
 	"github.com/go-redis/redis/v8"
@@ -12,6 +13,7 @@ import (
 
 type QueueService interface {
 	EnqueueCrawlJob(urlID uint) error
+	EnqueueSiteCrawlJob(urlID uint, maxDepth, maxPages int) error
 	ProcessCrawlJobs(ctx context.Context, crawlerService CrawlerService) error
 	CancelCrawlJob(urlID uint) error
 	ClearCancellation(urlID uint) error
@@ -19,10 +21,19 @@ type QueueService interface {
 }
 
 type CrawlJob struct {
-	URLID     uint      `json:"url_id"`
-	CreatedAt time.Time `json:"created_at"`
+	URLID     uint             `json:"url_id"`
+	Mode      models.CrawlMode `json:"mode,omitempty"`
+	MaxDepth  int              `json:"max_depth,omitempty"`
+	MaxPages  int              `json:"max_pages,omitempty"`
+	CreatedAt time.Time        `json:"created_at"`
 }
 
+// Site crawls fetch many pages, so they get a much longer budget than single pages
+const (
+	pageCrawlTimeout = 30 * time.Second
+	siteCrawlTimeout = 30 * time.Minute
+)
+
 type queueService struct {
 	redis *redis.Client
 }
@@ -32,10 +43,23 @@ func NewQueueService(redisClient *redis.Client) QueueService {
 }
 
 func (s *queueService) EnqueueCrawlJob(urlID uint) error {
-	job := CrawlJob{
-		URLID:     urlID,
-		CreatedAt: time.Now(),
-	}
+	return s.enqueue(CrawlJob{
+		URLID: urlID,
+		Mode:  models.CrawlModePage,
+	})
+}
+
+func (s *queueService) EnqueueSiteCrawlJob(urlID uint, maxDepth, maxPages int) error {
+	return s.enqueue(CrawlJob{
+		URLID:    urlID,
+		Mode:     models.CrawlModeSite,
+		MaxDepth: maxDepth,
+		MaxPages: maxPages,
+	})
+}
+
+func (s *queueService) enqueue(job CrawlJob) error {
+	job.CreatedAt = time.Now()
 
 	jobData, err := json.Marshal(job)
 	if err != nil {
@@ -84,8 +108,18 @@ func (s *queueService) ProcessCrawlJobs(ctx context.Context, crawlerService Craw
 				continue
 			}
 
+			if job.Mode == models.CrawlModeSite {
+				log.Printf("Processing site crawl job for URL ID: %d", job.URLID)
+				crawlCtx, crawlCancel := context.WithTimeout(ctx, siteCrawlTimeout)
+				if err := crawlerService.CrawlSite(crawlCtx, job); err != nil {
+					log.Printf("Error crawling site for URL ID %d: %v", job.URLID, err)
+				}
+				crawlCancel()
+				continue
+			}
+
 			log.Printf("Processing crawl job for URL ID: %d", job.URLID)
-			crawlCtx, crawlCancel := context.WithTimeout(ctx, 30*time.Second)
+			crawlCtx, crawlCancel := context.WithTimeout(ctx, pageCrawlTimeout)
 			if err := crawlerService.CrawlURL(crawlCtx, job.URLID); err != nil {
 				log.Printf("Error crawling URL ID %d: %v", job.URLID, err)
 			}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+)
+
+func init() {
+	RegisterAnalyzer(resourceAnalyzer{})
+}
+
+// resourceAnalyzer fills the resources of the crawl result and adds the
+// resources robots.txt disallows to its blocked URLs.
+type resourceAnalyzer struct{}
+
+func (resourceAnalyzer) Name() string {
+	return "resources"
+}
+
+func (resourceAnalyzer) Description() string {
+	return "Checks the scripts, stylesheets, fonts and other subresources pages load"
+}
+
+func (resourceAnalyzer) Analyze(input *AnalyzerInput) ([]models.Finding, error) {
+	result := input.Result
+	resources := input.crawler.analyzeResources(input.Document, input.PageURL, input.robots)
+	result.Resources = resources.Resources
+	for _, resource := range result.Resources {
+		if resource.Broken {
+			result.BrokenResources++
+		}
+	}
+	result.BlockedResources = len(resources.Blocked)
+	for _, target := range resources.Blocked {
+		result.BlockedURLs = append(result.BlockedURLs, models.BlockedURL{Kind: models.BlockedResource, URL: target})
+	}
+	return nil, nil
+}
+
+// iconRels are the link relations browsers and platforms load as page icons.
+var iconRels = []string{"icon", "apple-touch-icon", "apple-touch-icon-precomposed", "mask-icon"}
+
+// preloadKinds maps the as attribute of <link rel=preload> to a resource kind.
+var preloadKinds = map[string]string{
+	"font":   models.ResourceFont,
+	"script": models.ResourceScript,
+	"style":  models.ResourceStylesheet,
+}
+
+// resourceAnalysis lists the subresources referenced by a page.
+type resourceAnalysis struct {
+	Resources []models.Resource
+	Blocked   []string
+}
+
+// analyzeResources collects the subresources a page loads besides images and
+// checks each one with the link-check client. Every resource is returned with
+// its status so broken ones can be told apart by kind; when the crawl profile
+// turns resource checks off they are returned unchecked. Resources robots.txt
+// disallows are listed as blocked instead of being checked.
+func (s *crawlerService) analyzeResources(doc *goquery.Document, baseURL string, robots robotsPolicy) resourceAnalysis {
+	var analysis resourceAnalysis
+	parsedBase, err := url.Parse(baseURL)
+	if err != nil {
+		return analysis
+	}
+
+	var resources []models.Resource
+	seen := make(map[string]bool)
+	add := func(kind, ref string) {
+		target, ok := resolveSubresourceURL(parsedBase, ref)
+		if !ok || seen[target] {
+			return
+		}
+		seen[target] = true
+		if !robots.allowed(target) {
+			analysis.Blocked = append(analysis.Blocked, target)
+			return
+		}
+		resources = append(resources, models.Resource{Kind: kind, URL: target})
+	}
+
+	doc.Find("script[src], link[href], iframe[src], video[src], audio[src], video source[src], audio source[src], track[src], embed[src], object[data]").Each(func(_ int, element *goquery.Selection) {
+		switch goquery.NodeName(element) {
+		case "script":
+			add(models.ResourceScript, element.AttrOr("src", ""))
+		case "link":
+			if kind := linkResourceKind(element); kind != "" {
+				add(kind, element.AttrOr("href", ""))
+			}
+		case "iframe":
+			add(models.ResourceIframe, element.AttrOr("src", ""))
+		case "video", "audio", "source", "track":
+			add(models.ResourceMedia, element.AttrOr("src", ""))
+		case "embed":
+			add(models.ResourceObject, element.AttrOr("src", ""))
+		case "object":
+			add(models.ResourceObject, element.AttrOr("data", ""))
+		}
+	})
+
+	analysis.Resources = resources
+	if s.skipResourceChecks() {
+		return analysis
+	}
+
+	targets := make([]string, len(resources))
+	for i, resource := range resources {
+		targets[i] = resource.URL
+	}
+	for i, status := range s.checkURLs(targets, robots) {
+		resources[i].StatusCode = status.StatusCode
+		if status.Err != nil {
+			resources[i].ErrorMessage = status.Err.Error()
+		}
+		resources[i].Broken = status.Err != nil || status.StatusCode >= 400
+	}
+
+	return analysis
+}
+
+// linkResourceKind returns the kind of resource a <link> element loads, or ""
+// for relations such as canonical or alternate that only point elsewhere.
+func linkResourceKind(link *goquery.Selection) string {
+	switch {
+	case hasRel(link, "stylesheet"):
+		return models.ResourceStylesheet
+	case hasRel(link, "modulepreload"):
+		return models.ResourceScript
+	case hasRel(link, "preload"):
+		as := strings.ToLower(strings.TrimSpace(link.AttrOr("as", "")))
+		if as == "image" {
+			// Preloaded images are covered by the image audit
+			return ""
+		}
+		if kind, ok := preloadKinds[as]; ok {
+			return kind
+		}
+		return models.ResourcePreload
+	case hasRel(link, "manifest"):
+		return models.ResourceManifest
+	}
+	for _, rel := range iconRels {
+		if hasRel(link, rel) {
+			return models.ResourceIcon
+		}
+	}
+	return ""
+}
+
+// resolveSubresourceURL resolves ref against base and reports whether the
+// result is an http(s) URL worth checking. Fragments are dropped since they
+// never reach the server.
+func resolveSubresourceURL(base *url.URL, ref string) (string, bool) {
+	ref = strings.TrimSpace(ref)
+	if ref == "" {
+		return "", false
+	}
+	parsedRef, err := url.Parse(ref)
+	if err != nil {
+		return "", false
+	}
+	absolute := base.ResolveReference(parsedRef)
+	if absolute.Scheme != "http" && absolute.Scheme != "https" {
+		return "", false
+	}
+	absolute.Fragment = ""
+	return absolute.String(), true
+}
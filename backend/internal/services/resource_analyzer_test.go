@@ -0,0 +1,105 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+)
+
+func TestAnalyzeResources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "missing") {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	html := `<html><head>
+<link rel="stylesheet" href="/style.css">
+<link rel="preload" href="/missing-font.woff2" as="font" crossorigin>
+<link rel="preload" href="/hero.png" as="image">
+<link rel="shortcut icon" href="/favicon.ico">
+<link rel="canonical" href="/page">
+<link rel="manifest" href="/site.webmanifest">
+<script src="/app.js"></script>
+<script src="/app.js"></script>
+<script>inline()</script>
+</head><body>
+<iframe src="/missing-embed"></iframe>
+<iframe src="about:blank"></iframe>
+<video src="/intro.mp4"><track src="/intro.vtt"></video>
+<audio><source src="/missing-audio.mp3" type="audio/mpeg"></audio>
+<picture><source srcset="/hero.webp"></picture>
+<object data="/doc.pdf"></object>
+</body></html>`
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	service := NewCrawlerService(nil, nil, nil, nil, createTestConfig()).(*crawlerService)
+	resources := service.analyzeResources(doc, server.URL+"/page", robotsPolicy{}).Resources
+
+	expected := []struct {
+		kind   string
+		path   string
+		broken bool
+	}{
+		{models.ResourceStylesheet, "/style.css", false},
+		{models.ResourceFont, "/missing-font.woff2", true},
+		{models.ResourceIcon, "/favicon.ico", false},
+		{models.ResourceManifest, "/site.webmanifest", false},
+		{models.ResourceScript, "/app.js", false},
+		{models.ResourceIframe, "/missing-embed", true},
+		{models.ResourceMedia, "/intro.mp4", false},
+		{models.ResourceMedia, "/intro.vtt", false},
+		{models.ResourceMedia, "/missing-audio.mp3", true},
+		{models.ResourceObject, "/doc.pdf", false},
+	}
+	if len(resources) != len(expected) {
+		t.Fatalf("Expected %d resources, got %d: %+v", len(expected), len(resources), resources)
+	}
+	for i, want := range expected {
+		got := resources[i]
+		if got.Kind != want.kind || got.URL != server.URL+want.path || got.Broken != want.broken {
+			t.Errorf("Expected %s %s (broken=%v), got %s %s (broken=%v)", want.kind, want.path, want.broken, got.Kind, got.URL, got.Broken)
+		}
+		if want.broken && got.StatusCode != http.StatusNotFound {
+			t.Errorf("Expected status 404 for %s, got %d", got.URL, got.StatusCode)
+		}
+	}
+}
+
+func TestAnalyzeResources_RecordsBlockedResources(t *testing.T) {
+	var checked []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			w.Write([]byte("User-agent: *\nDisallow: /private/\n"))
+			return
+		}
+		checked = append(checked, r.URL.Path)
+	}))
+	defer server.Close()
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<script src="/app.js"></script><script src="/private/tracker.js"></script>`))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	service := NewCrawlerService(nil, nil, nil, nil, createTestConfig()).(*crawlerService)
+	analysis := service.analyzeResources(doc, server.URL+"/page", service.robotsPolicyFor(&models.URL{URL: server.URL}))
+
+	if !reflect.DeepEqual(analysis.Blocked, []string{server.URL + "/private/tracker.js"}) {
+		t.Errorf("Expected the disallowed script to be listed as blocked, got %v", analysis.Blocked)
+	}
+	if len(analysis.Resources) != 1 || !reflect.DeepEqual(checked, []string{"/app.js"}) {
+		t.Errorf("Expected only the allowed script to be checked, got %+v and %v", analysis.Resources, checked)
+	}
+}
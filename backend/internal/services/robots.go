@@ -0,0 +1,401 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sykell-crawler/internal/models"
+	"sync"
+	"time"
+)
+
+var errBlockedByRobots = errors.New("blocked by robots.txt")
+
+// maxCrawlDelay caps the Crawl-delay we honour so a hostile robots.txt can't
+// stall the worker indefinitely.
+const maxCrawlDelay = 10 * time.Second
+
+// maxRobotsSize is the most of a robots.txt file we read, as suggested by RFC 9309.
+const maxRobotsSize = 500 * 1024
+
+// robotsRetryInterval is how long a robots.txt that couldn't be fetched
+// because of a network error is cached, so a brief outage doesn't disable
+// robots.txt for the whole cache TTL.
+const robotsRetryInterval = time.Minute
+
+type robotsRule struct {
+	allow   bool
+	pattern string
+	matcher *regexp.Regexp
+}
+
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay time.Duration
+}
+
+// robotsFile is a parsed robots.txt.
+type robotsFile struct {
+	groups   []robotsGroup
+	sitemaps []string
+	// disallowAll is set when the file couldn't be fetched because of a server error
+	disallowAll bool
+}
+
+// parseRobots parses a robots.txt body. Unknown directives and malformed lines
+// are ignored, as required by RFC 9309.
+func parseRobots(r io.Reader) *robotsFile {
+	file := &robotsFile{}
+	var current *robotsGroup
+	inAgents := false
+
+	scanner := bufio.NewScanner(io.LimitReader(r, maxRobotsSize))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, '#'); i >= 0 {
+			line = line[:i]
+		}
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			if !inAgents {
+				file.groups = append(file.groups, robotsGroup{})
+				current = &file.groups[len(file.groups)-1]
+				inAgents = true
+			}
+			current.agents = append(current.agents, strings.ToLower(value))
+		case "allow", "disallow":
+			inAgents = false
+			if current == nil || value == "" {
+				continue
+			}
+			current.rules = append(current.rules, robotsRule{
+				allow:   key == "allow",
+				pattern: value,
+				matcher: compileRobotsPattern(value),
+			})
+		case "crawl-delay":
+			inAgents = false
+			if current == nil {
+				continue
+			}
+			if seconds, err := strconv.ParseFloat(value, 64); err == nil && seconds >= 0 {
+				current.crawlDelay = time.Duration(seconds * float64(time.Second))
+			}
+		case "sitemap":
+			if value != "" {
+				file.sitemaps = append(file.sitemaps, value)
+			}
+		}
+	}
+
+	return file
+}
+
+// compileRobotsPattern turns a robots.txt path pattern into a regexp, where
+// '*' matches any sequence of characters and a trailing '$' anchors the end.
+func compileRobotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	pattern = strings.TrimSuffix(pattern, "$")
+
+	parts := strings.Split(pattern, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+
+	expr := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expr += "$"
+	}
+	return regexp.MustCompile(expr)
+}
+
+// groupFor returns the rules and crawl delay that apply to the given user
+// agent. Groups naming its product token take precedence over the '*' group,
+// and multiple groups for the same agent are merged. As RFC 9309 requires,
+// the whole token must match, so "User-agent: s" doesn't apply to
+// sykell-crawler.
+func (f *robotsFile) groupFor(userAgent string) robotsGroup {
+	token := robotsAgentToken(userAgent)
+
+	var specific, wildcard robotsGroup
+	var hasSpecific bool
+	for _, group := range f.groups {
+		for _, agent := range group.agents {
+			switch {
+			case agent == "*":
+				wildcard.rules = append(wildcard.rules, group.rules...)
+				wildcard.crawlDelay = maxDuration(wildcard.crawlDelay, group.crawlDelay)
+			case agent != "" && agent == token:
+				hasSpecific = true
+				specific.rules = append(specific.rules, group.rules...)
+				specific.crawlDelay = maxDuration(specific.crawlDelay, group.crawlDelay)
+			default:
+				continue
+			}
+			break
+		}
+	}
+
+	if hasSpecific {
+		return specific
+	}
+	return wildcard
+}
+
+// allowed reports whether the user agent may fetch the path. The longest
+// matching rule wins, and Allow wins over Disallow when they are equally long.
+func (f *robotsFile) allowed(userAgent, path string) bool {
+	if f.disallowAll {
+		return false
+	}
+	if path == "/robots.txt" {
+		return true
+	}
+
+	var best *robotsRule
+	group := f.groupFor(userAgent)
+	for i, rule := range group.rules {
+		if !rule.matcher.MatchString(path) {
+			continue
+		}
+		if best == nil || len(rule.pattern) > len(best.pattern) ||
+			(len(rule.pattern) == len(best.pattern) && rule.allow && !best.allow) {
+			best = &group.rules[i]
+		}
+	}
+
+	return best == nil || best.allow
+}
+
+func (f *robotsFile) crawlDelay(userAgent string) time.Duration {
+	if f.disallowAll {
+		return 0
+	}
+	delay := f.groupFor(userAgent).crawlDelay
+	if delay > maxCrawlDelay {
+		return maxCrawlDelay
+	}
+	return delay
+}
+
+// robotsAgentToken returns the product token robots.txt groups are matched
+// against for a User-Agent: the product named after "compatible;" in
+// browser-style agents such as "Mozilla/5.0 (compatible; Googlebot/2.1)",
+// otherwise the first product, without its version.
+func robotsAgentToken(userAgent string) string {
+	userAgent = strings.ToLower(userAgent)
+	if _, product, found := strings.Cut(userAgent, "compatible;"); found {
+		userAgent = product
+	}
+	fields := strings.FieldsFunc(userAgent, func(r rune) bool {
+		return r == ' ' || r == ';' || r == '(' || r == ')'
+	})
+	if len(fields) == 0 {
+		return ""
+	}
+	token, _, _ := strings.Cut(fields[0], "/")
+	return token
+}
+
+func maxDuration(a, b time.Duration) time.Duration {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+type robotsEntry struct {
+	ready     chan struct{}
+	file      *robotsFile
+	expiresAt time.Time
+}
+
+// robotsKey identifies a cached robots.txt. Sites may serve a different file
+// per User-Agent, so each agent gets its own copy.
+type robotsKey struct {
+	userAgent string
+	origin    string
+}
+
+// robotsCache fetches robots.txt once per host and User-Agent and keeps it for
+// the cache TTL. It also spaces out requests to hosts that ask for a
+// Crawl-delay. userAgent is the agent used unless a crawl profile sets one.
+type robotsCache struct {
+	client    *http.Client
+	userAgent string
+	ttl       time.Duration
+
+	mu          sync.Mutex
+	entries     map[robotsKey]*robotsEntry
+	nextAllowed map[string]time.Time
+}
+
+func newRobotsCache(client *http.Client, userAgent string, ttl time.Duration) *robotsCache {
+	return &robotsCache{
+		client:      client,
+		userAgent:   userAgent,
+		ttl:         ttl,
+		entries:     make(map[robotsKey]*robotsEntry),
+		nextAllowed: make(map[string]time.Time),
+	}
+}
+
+// get returns the robots.txt the URL's host serves to userAgent, fetching it
+// if it isn't cached. Concurrent callers for the same host and agent share a
+// single fetch.
+func (c *robotsCache) get(target *url.URL, userAgent string) *robotsFile {
+	key := robotsKey{userAgent: userAgent, origin: strings.ToLower(target.Scheme + "://" + target.Host)}
+
+	c.mu.Lock()
+	entry, exists := c.entries[key]
+	if !exists || (!entry.expiresAt.IsZero() && time.Now().After(entry.expiresAt)) {
+		entry = &robotsEntry{ready: make(chan struct{})}
+		c.entries[key] = entry
+		c.mu.Unlock()
+
+		file, fetched := c.fetch(key.origin+"/robots.txt", userAgent)
+		ttl := c.ttl
+		if !fetched {
+			ttl = robotsRetryInterval
+		}
+		c.mu.Lock()
+		entry.file = file
+		entry.expiresAt = time.Now().Add(ttl)
+		c.mu.Unlock()
+		close(entry.ready)
+		return file
+	}
+	c.mu.Unlock()
+
+	<-entry.ready
+	return entry.file
+}
+
+// fetch downloads and parses a robots.txt. Per RFC 9309 a missing file allows
+// everything and a server error disallows everything. Network errors also
+// allow everything, so the fetch itself can surface the real failure. fetched
+// is false after a network or server error, which may be temporary. The file
+// is fetched with the given User-Agent but never with a crawl profile's
+// headers, cookies or credentials, since it is shared by every crawl that
+// uses the same agent.
+func (c *robotsCache) fetch(robotsURL, userAgent string) (file *robotsFile, fetched bool) {
+	req, err := http.NewRequest(http.MethodGet, robotsURL, nil)
+	if err != nil {
+		return &robotsFile{}, true
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return &robotsFile{}, false
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 500:
+		return &robotsFile{disallowAll: true}, false
+	case resp.StatusCode >= 400:
+		return &robotsFile{}, true
+	}
+
+	return parseRobots(resp.Body), true
+}
+
+func (c *robotsCache) allowed(target *url.URL, userAgent string) bool {
+	path := target.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+	if target.RawQuery != "" {
+		path += "?" + target.RawQuery
+	}
+	return c.get(target, userAgent).allowed(userAgent, path)
+}
+
+// wait blocks until the host's Crawl-delay has passed since the last request
+// it let through. Slots are reserved up front so concurrent callers queue up.
+func (c *robotsCache) wait(target *url.URL, userAgent string) {
+	delay := c.get(target, userAgent).crawlDelay(userAgent)
+	if delay <= 0 {
+		return
+	}
+
+	host := strings.ToLower(target.Host)
+	c.mu.Lock()
+	now := time.Now()
+	slot := c.nextAllowed[host]
+	if slot.Before(now) {
+		slot = now
+	}
+	c.nextAllowed[host] = slot.Add(delay)
+	c.mu.Unlock()
+
+	time.Sleep(time.Until(slot))
+}
+
+// robotsPolicy applies robots.txt to a single crawl. Groups are matched for
+// the User-Agent the crawl sends, which is the crawl profile's when it sets
+// one. When the crawled URL has opted out of robots.txt, its own host is
+// exempt; other hosts never are.
+type robotsPolicy struct {
+	cache      *robotsCache
+	userAgent  string
+	exemptHost string
+}
+
+func (s *crawlerService) robotsPolicyFor(urlModel *models.URL) robotsPolicy {
+	policy := robotsPolicy{cache: s.robots}
+	if s.robots != nil {
+		policy.userAgent = s.robots.userAgent
+	}
+	if s.profile != nil && s.profile.UserAgent != "" {
+		policy.userAgent = s.profile.UserAgent
+	}
+	if urlModel.IgnoreRobots {
+		if parsed, err := url.Parse(urlModel.URL); err == nil {
+			policy.exemptHost = strings.ToLower(parsed.Host)
+		}
+	}
+	return policy
+}
+
+func (p robotsPolicy) exempt(target *url.URL) bool {
+	return p.cache == nil || (p.exemptHost != "" && strings.ToLower(target.Host) == p.exemptHost)
+}
+
+// allowed reports whether the crawl may fetch the target URL.
+func (p robotsPolicy) allowed(target string) bool {
+	parsed, err := url.Parse(target)
+	if err != nil || p.exempt(parsed) {
+		return true
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return true
+	}
+	return p.cache.allowed(parsed, p.userAgent)
+}
+
+// wait honours the target host's Crawl-delay before a request.
+func (p robotsPolicy) wait(target string) {
+	parsed, err := url.Parse(target)
+	if err != nil || p.exempt(parsed) {
+		return
+	}
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return
+	}
+	p.cache.wait(parsed, p.userAgent)
+}
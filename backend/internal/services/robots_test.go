@@ -0,0 +1,345 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sykell-crawler/internal/models"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const testRobots = `
+# Comment line
+User-agent: *
+Disallow: /private/
+Allow: /private/public-page
+Disallow: /*.pdf$
+Crawl-delay: 2
+
+User-agent: sykell-crawler
+User-agent: otherbot
+Disallow: /admin
+Allow: /admin/help
+Disallow: /search?*q=
+Crawl-delay: 1.5
+
+Sitemap: https://example.com/sitemap.xml
+`
+
+func TestParseRobots_SpecificGroup(t *testing.T) {
+	file := parseRobots(strings.NewReader(testRobots))
+
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{"/", true},
+		{"/admin", false},
+		{"/admin/settings", false},
+		{"/admin/help", true},
+		{"/search?page=1&q=test", false},
+		{"/search?page=1", true},
+		// The specific group replaces the wildcard group entirely
+		{"/private/data", true},
+		{"/robots.txt", true},
+	}
+
+	for _, tt := range tests {
+		if allowed := file.allowed("sykell-crawler/1.0", tt.path); allowed != tt.allowed {
+			t.Errorf("Expected allowed(%s) to be %t, got %t", tt.path, tt.allowed, allowed)
+		}
+	}
+
+	if delay := file.crawlDelay("sykell-crawler"); delay != 1500*time.Millisecond {
+		t.Errorf("Expected crawl delay 1.5s, got %v", delay)
+	}
+
+	if len(file.sitemaps) != 1 || file.sitemaps[0] != "https://example.com/sitemap.xml" {
+		t.Errorf("Expected sitemap to be parsed, got %v", file.sitemaps)
+	}
+}
+
+func TestParseRobots_WildcardGroup(t *testing.T) {
+	file := parseRobots(strings.NewReader(testRobots))
+
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{"/admin", true},
+		{"/private/data", false},
+		{"/private/public-page", true},
+		{"/files/report.pdf", false},
+		{"/files/report.pdf?download=1", true},
+	}
+
+	for _, tt := range tests {
+		if allowed := file.allowed("somebot", tt.path); allowed != tt.allowed {
+			t.Errorf("Expected allowed(%s) to be %t, got %t", tt.path, tt.allowed, allowed)
+		}
+	}
+
+	if delay := file.crawlDelay("somebot"); delay != 2*time.Second {
+		t.Errorf("Expected crawl delay 2s, got %v", delay)
+	}
+}
+
+func TestParseRobots_MatchesWholeProductToken(t *testing.T) {
+	file := parseRobots(strings.NewReader("User-agent: s\nUser-agent: sykell\nDisallow: /\n\nUser-agent: SYKELL-CRAWLER\nDisallow: /admin\n"))
+
+	if !file.allowed("sykell-crawler/1.0", "/page") {
+		t.Error("Expected groups naming a prefix of the agent not to apply")
+	}
+	if file.allowed("sykell-crawler/1.0", "/admin") {
+		t.Error("Expected the group naming the agent to apply regardless of case")
+	}
+}
+
+func TestParseRobots_AllowWinsTie(t *testing.T) {
+	file := parseRobots(strings.NewReader("User-agent: *\nDisallow: /page\nAllow: /page\n"))
+
+	if !file.allowed("sykell-crawler", "/page") {
+		t.Error("Expected Allow to win over an equally specific Disallow")
+	}
+}
+
+func TestRobotsCache_FetchesOncePerHost(t *testing.T) {
+	var fetches int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			atomic.AddInt32(&fetches, 1)
+			w.Write([]byte("User-agent: *\nDisallow: /blocked\n"))
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	cache := newRobotsCache(http.DefaultClient, "sykell-crawler", time.Hour)
+	blocked, _ := url.Parse(server.URL + "/blocked")
+	open, _ := url.Parse(server.URL + "/open")
+
+	if cache.allowed(blocked, "sykell-crawler") {
+		t.Error("Expected /blocked to be disallowed")
+	}
+	if !cache.allowed(open, "sykell-crawler") {
+		t.Error("Expected /open to be allowed")
+	}
+	if fetches != 1 {
+		t.Errorf("Expected robots.txt to be fetched once, got %d", fetches)
+	}
+}
+
+func TestRobotsCache_ServerErrorDisallowsAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	cache := newRobotsCache(http.DefaultClient, "sykell-crawler", time.Hour)
+	target, _ := url.Parse(server.URL + "/page")
+
+	if cache.allowed(target, "sykell-crawler") {
+		t.Error("Expected a 5xx robots.txt to disallow everything")
+	}
+}
+
+func TestRobotsAgentToken(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		token     string
+	}{
+		{"sykell-crawler", "sykell-crawler"},
+		{"ProfileBot/1.0", "profilebot"},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", "googlebot"},
+		{"Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0", "mozilla"},
+		{"", ""},
+	}
+	for _, test := range tests {
+		if token := robotsAgentToken(test.userAgent); token != test.token {
+			t.Errorf("robotsAgentToken(%q) = %q; expected %q", test.userAgent, token, test.token)
+		}
+	}
+}
+
+func TestCrawlURL_MatchesRobotsForProfileUserAgent(t *testing.T) {
+	var mu sync.Mutex
+	robotsAgents := make(map[string]int)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			mu.Lock()
+			robotsAgents[r.Header.Get("User-Agent")]++
+			mu.Unlock()
+			w.Write([]byte("User-agent: googlebot\nDisallow: /\n\nUser-agent: *\nAllow: /\n"))
+		default:
+			w.Write([]byte(`<html><head><title>Page</title></head></html>`))
+		}
+	}))
+	defer server.Close()
+
+	cfg := createTestConfig()
+	cfg.RobotsUserAgent = "sykell-crawler"
+	profile := &models.CrawlProfile{ID: 1, Name: "googlebot", UserAgent: "Mozilla/5.0 (compatible; Googlebot/2.1)"}
+	urlRepo := &mockURLRepository{
+		urls: map[uint]*models.URL{
+			1: {ID: 1, URL: server.URL + "/page", Status: models.StatusQueued},
+			2: {ID: 2, URL: server.URL + "/page", Status: models.StatusQueued, CrawlProfileID: &profile.ID, CrawlProfile: profile},
+		},
+	}
+	resultRepo := &mockCrawlResultRepository{
+		results: make(map[uint]*models.CrawlResult),
+	}
+
+	service := NewCrawlerService(urlRepo, resultRepo, nil, &mockQueueService{}, cfg)
+	for id := uint(1); id <= 2; id++ {
+		if err := service.CrawlURL(context.Background(), id); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+	}
+
+	if resultRepo.results[1].BlockedByRobots {
+		t.Error("Expected the default agent to be allowed")
+	}
+	if !resultRepo.results[2].BlockedByRobots {
+		t.Error("Expected the profile's agent to be matched against its own group")
+	}
+	if robotsAgents["sykell-crawler"] != 1 || robotsAgents[profile.UserAgent] != 1 {
+		t.Errorf("Expected robots.txt to be fetched once per agent, got %v", robotsAgents)
+	}
+}
+
+func TestRobotsCache_RetriesFailedFetchesSooner(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	ok, _ := url.Parse(server.URL + "/page")
+	unreachable, _ := url.Parse("http://127.0.0.1:1/page")
+
+	cache := newRobotsCache(http.DefaultClient, "sykell-crawler", time.Hour)
+	if !cache.allowed(unreachable, "sykell-crawler") || !cache.allowed(ok, "sykell-crawler") {
+		t.Fatal("Expected both hosts to be allowed")
+	}
+
+	expiry := func(target *url.URL) time.Duration {
+		key := robotsKey{userAgent: "sykell-crawler", origin: strings.ToLower(target.Scheme + "://" + target.Host)}
+		return time.Until(cache.entries[key].expiresAt)
+	}
+	if remaining := expiry(unreachable); remaining > robotsRetryInterval {
+		t.Errorf("Expected a failed fetch to be retried within %v, got %v", robotsRetryInterval, remaining)
+	}
+	if remaining := expiry(ok); remaining <= robotsRetryInterval {
+		t.Errorf("Expected a fetched robots.txt to be kept for the TTL, got %v", remaining)
+	}
+}
+
+func TestCrawlURL_BlockedByRobots(t *testing.T) {
+	var pageFetched int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.Write([]byte("User-agent: *\nDisallow: /\n"))
+		default:
+			atomic.AddInt32(&pageFetched, 1)
+			w.Write([]byte(`<html><head><title>Secret</title></head></html>`))
+		}
+	}))
+	defer server.Close()
+
+	urlRepo := &mockURLRepository{
+		urls: map[uint]*models.URL{
+			1: {ID: 1, URL: server.URL + "/page", Status: models.StatusQueued},
+		},
+	}
+	resultRepo := &mockCrawlResultRepository{
+		results: make(map[uint]*models.CrawlResult),
+	}
+
+	service := NewCrawlerService(urlRepo, resultRepo, nil, &mockQueueService{}, createTestConfig())
+	if err := service.CrawlURL(context.Background(), 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	if pageFetched != 0 {
+		t.Error("Expected the disallowed page not to be fetched")
+	}
+	if !resultRepo.results[1].BlockedByRobots {
+		t.Error("Expected the result to be marked as blocked by robots")
+	}
+}
+
+func TestCrawlURL_IgnoreRobotsOverride(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.Write([]byte("User-agent: *\nDisallow: /\n"))
+		default:
+			w.Write([]byte(`<html><head><title>Ours</title></head><body><a href="/other">Other</a></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	urlRepo := &mockURLRepository{
+		urls: map[uint]*models.URL{
+			1: {ID: 1, URL: server.URL + "/page", Status: models.StatusQueued, IgnoreRobots: true},
+		},
+	}
+	resultRepo := &mockCrawlResultRepository{
+		results: make(map[uint]*models.CrawlResult),
+	}
+
+	service := NewCrawlerService(urlRepo, resultRepo, nil, &mockQueueService{}, createTestConfig())
+	if err := service.CrawlURL(context.Background(), 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	result := resultRepo.results[1]
+	if result.Title != "Ours" {
+		t.Errorf("Expected the page to be crawled, got title '%s'", result.Title)
+	}
+	if result.BlockedLinks != 0 {
+		t.Errorf("Expected no blocked links on an exempt host, got %d", result.BlockedLinks)
+	}
+}
+
+func TestCrawlURL_SendsRobotsUserAgent(t *testing.T) {
+	var mu sync.Mutex
+	agents := make(map[string]string)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		agents[r.URL.Path] = r.Header.Get("User-Agent")
+		mu.Unlock()
+		if r.URL.Path == "/" {
+			w.Write([]byte(`<html><body><a href="/about">About</a><img src="/logo.png" alt=""></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	urlRepo := &mockURLRepository{
+		urls: map[uint]*models.URL{
+			1: {ID: 1, URL: server.URL, Status: models.StatusQueued},
+		},
+	}
+	resultRepo := &mockCrawlResultRepository{
+		results: make(map[uint]*models.CrawlResult),
+	}
+	cfg := createTestConfig()
+	cfg.RobotsUserAgent = "sykell-crawler"
+
+	service := NewCrawlerService(urlRepo, resultRepo, nil, &mockQueueService{}, cfg)
+	if err := service.CrawlURL(context.Background(), 1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	for _, path := range []string{"/robots.txt", "/", "/about", "/logo.png"} {
+		if agents[path] != "sykell-crawler" {
+			t.Errorf("Expected %s to be requested as sykell-crawler, got %q", path, agents[path])
+		}
+	}
+}
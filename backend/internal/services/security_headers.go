@@ -0,0 +1,273 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"sykell-crawler/internal/models"
+)
+
+const (
+	// hstsMinMaxAge is the shortest HSTS max-age (about six months) that is
+	// not flagged as too short.
+	hstsMinMaxAge = 15768000
+	// hstsPreloadMaxAge is the max-age the HSTS preload list requires.
+	hstsPreloadMaxAge = 31536000
+)
+
+// referrerPolicyGrades grades each Referrer-Policy token by how much of the
+// URL it leaks to other origins.
+var referrerPolicyGrades = map[string]string{
+	"no-referrer":                     models.SecurityPass,
+	"same-origin":                     models.SecurityPass,
+	"strict-origin":                   models.SecurityPass,
+	"strict-origin-when-cross-origin": models.SecurityPass,
+	"origin":                          models.SecurityWarn,
+	"origin-when-cross-origin":        models.SecurityWarn,
+	"no-referrer-when-downgrade":      models.SecurityWarn,
+	"unsafe-url":                      models.SecurityFail,
+}
+
+// auditSecurityHeaders grades the security headers of a page response.
+// HSTS and the Secure cookie flag are only required when the page was served
+// over HTTPS.
+func auditSecurityHeaders(header http.Header, isHTTPS bool) *models.SecurityAudit {
+	csp := header.Get("Content-Security-Policy")
+	checks := []models.SecurityCheck{
+		checkContentSecurityPolicy(csp, header.Get("Content-Security-Policy-Report-Only")),
+		checkStrictTransportSecurity(header.Get("Strict-Transport-Security"), isHTTPS),
+		checkFrameOptions(header.Get("X-Frame-Options"), csp),
+		checkContentTypeOptions(header.Get("X-Content-Type-Options")),
+		checkReferrerPolicy(header.Get("Referrer-Policy")),
+		checkPermissionsPolicy(header.Get("Permissions-Policy")),
+	}
+	checks = append(checks, checkCookies(header, isHTTPS)...)
+
+	audit := &models.SecurityAudit{
+		Headers: redactCookies(header),
+		Checks:  checks,
+	}
+	for _, check := range checks {
+		switch check.Grade {
+		case models.SecurityPass:
+			audit.Passed++
+		case models.SecurityWarn:
+			audit.Warnings++
+		case models.SecurityFail:
+			audit.Failures++
+		}
+	}
+	return audit
+}
+
+func securityCheck(header, value, grade, message string) models.SecurityCheck {
+	return models.SecurityCheck{Header: header, Value: value, Grade: grade, Message: message}
+}
+
+// parseDirectives splits a policy such as CSP into lowercase directive names
+// and their values.
+func parseDirectives(policy string) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(policy, ";") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		name := strings.ToLower(fields[0])
+		if _, exists := directives[name]; !exists {
+			directives[name] = strings.Join(fields[1:], " ")
+		}
+	}
+	return directives
+}
+
+func checkContentSecurityPolicy(policy, reportOnly string) models.SecurityCheck {
+	const name = "Content-Security-Policy"
+	if policy == "" {
+		if reportOnly != "" {
+			return securityCheck(name, reportOnly, models.SecurityWarn, "policy is only reported, not enforced")
+		}
+		return securityCheck(name, "", models.SecurityFail, "header is missing")
+	}
+
+	directives := parseDirectives(policy)
+	scriptSources, hasScriptSrc := directives["script-src"]
+	if !hasScriptSrc {
+		scriptSources, hasScriptSrc = directives["default-src"]
+	}
+	if !hasScriptSrc {
+		return securityCheck(name, policy, models.SecurityWarn, "no script-src or default-src directive restricts scripts")
+	}
+	lowerSources := strings.ToLower(scriptSources)
+	if strings.Contains(lowerSources, "'unsafe-inline'") || strings.Contains(lowerSources, "'unsafe-eval'") {
+		return securityCheck(name, policy, models.SecurityWarn, "scripts allow 'unsafe-inline' or 'unsafe-eval'")
+	}
+	return securityCheck(name, policy, models.SecurityPass, "scripts are restricted")
+}
+
+func checkStrictTransportSecurity(value string, isHTTPS bool) models.SecurityCheck {
+	const name = "Strict-Transport-Security"
+	if !isHTTPS {
+		return securityCheck(name, value, models.SecurityWarn, "page is not served over HTTPS")
+	}
+	if value == "" {
+		return securityCheck(name, "", models.SecurityFail, "header is missing")
+	}
+
+	maxAge, hasMaxAge := -1, false
+	var includeSubDomains, preload bool
+	for _, directive := range strings.Split(value, ";") {
+		directiveName, directiveValue, _ := strings.Cut(strings.TrimSpace(directive), "=")
+		switch strings.ToLower(strings.TrimSpace(directiveName)) {
+		case "max-age":
+			if n, err := strconv.Atoi(strings.Trim(strings.TrimSpace(directiveValue), `"`)); err == nil {
+				maxAge, hasMaxAge = n, true
+			}
+		case "includesubdomains":
+			includeSubDomains = true
+		case "preload":
+			preload = true
+		}
+	}
+	if !hasMaxAge {
+		return securityCheck(name, value, models.SecurityFail, "max-age is missing or invalid")
+	}
+	if maxAge <= 0 {
+		return securityCheck(name, value, models.SecurityFail, "max-age=0 disables HSTS")
+	}
+
+	if preload && (maxAge < hstsPreloadMaxAge || !includeSubDomains) {
+		return securityCheck(name, value, models.SecurityWarn,
+			fmt.Sprintf("preload requires includeSubDomains and a max-age of at least %d", hstsPreloadMaxAge))
+	}
+	if maxAge < hstsMinMaxAge {
+		return securityCheck(name, value, models.SecurityWarn, fmt.Sprintf("max-age %d is shorter than six months", maxAge))
+	}
+	return securityCheck(name, value, models.SecurityPass, fmt.Sprintf("max-age %d", maxAge))
+}
+
+func checkFrameOptions(value, csp string) models.SecurityCheck {
+	const name = "X-Frame-Options"
+	switch strings.ToUpper(strings.TrimSpace(value)) {
+	case "DENY", "SAMEORIGIN":
+		return securityCheck(name, value, models.SecurityPass, "framing is restricted")
+	case "":
+		if _, ok := parseDirectives(csp)["frame-ancestors"]; ok {
+			return securityCheck(name, "", models.SecurityPass, "framing is restricted by CSP frame-ancestors")
+		}
+		return securityCheck(name, "", models.SecurityFail, "header is missing and CSP has no frame-ancestors")
+	}
+	if strings.HasPrefix(strings.ToUpper(strings.TrimSpace(value)), "ALLOW-FROM") {
+		return securityCheck(name, value, models.SecurityWarn, "ALLOW-FROM is ignored by modern browsers")
+	}
+	return securityCheck(name, value, models.SecurityFail, "value is not DENY or SAMEORIGIN")
+}
+
+func checkContentTypeOptions(value string) models.SecurityCheck {
+	const name = "X-Content-Type-Options"
+	switch {
+	case value == "":
+		return securityCheck(name, "", models.SecurityFail, "header is missing")
+	case strings.EqualFold(strings.TrimSpace(value), "nosniff"):
+		return securityCheck(name, value, models.SecurityPass, "MIME sniffing is disabled")
+	default:
+		return securityCheck(name, value, models.SecurityFail, "value is not nosniff")
+	}
+}
+
+func checkReferrerPolicy(value string) models.SecurityCheck {
+	const name = "Referrer-Policy"
+	if value == "" {
+		return securityCheck(name, "", models.SecurityWarn, "header is missing; browsers fall back to their default policy")
+	}
+
+	// Browsers apply the last policy they understand, so older fallbacks can
+	// be listed first
+	grade := ""
+	for _, token := range strings.Split(value, ",") {
+		if tokenGrade, ok := referrerPolicyGrades[strings.ToLower(strings.TrimSpace(token))]; ok {
+			grade = tokenGrade
+		}
+	}
+	switch grade {
+	case models.SecurityPass:
+		return securityCheck(name, value, grade, "cross-origin requests do not receive the full URL")
+	case models.SecurityWarn:
+		return securityCheck(name, value, grade, "policy can leak the origin or full URL to other sites")
+	case models.SecurityFail:
+		return securityCheck(name, value, grade, "unsafe-url sends the full URL to every site")
+	}
+	return securityCheck(name, value, models.SecurityFail, "no recognised policy")
+}
+
+func checkPermissionsPolicy(value string) models.SecurityCheck {
+	const name = "Permissions-Policy"
+	if value == "" {
+		return securityCheck(name, "", models.SecurityWarn, "header is missing")
+	}
+	return securityCheck(name, value, models.SecurityPass, "browser features are restricted")
+}
+
+// checkCookies grades every cookie set by the response on its Secure,
+// HttpOnly and SameSite attributes.
+func checkCookies(header http.Header, isHTTPS bool) []models.SecurityCheck {
+	const name = "Set-Cookie"
+	var checks []models.SecurityCheck
+	for _, cookie := range (&http.Response{Header: header}).Cookies() {
+		var problems []string
+		grade := models.SecurityPass
+
+		if !cookie.Secure && isHTTPS {
+			problems = append(problems, "missing Secure")
+			grade = models.SecurityFail
+		}
+		if cookie.SameSite == http.SameSiteNoneMode && !cookie.Secure {
+			problems = append(problems, "SameSite=None without Secure is rejected by browsers")
+			grade = models.SecurityFail
+		}
+		if !cookie.HttpOnly {
+			problems = append(problems, "missing HttpOnly")
+			if grade == models.SecurityPass {
+				grade = models.SecurityWarn
+			}
+		}
+		if cookie.SameSite == 0 {
+			problems = append(problems, "missing SameSite")
+			if grade == models.SecurityPass {
+				grade = models.SecurityWarn
+			}
+		}
+
+		message := "Secure, HttpOnly and SameSite are set"
+		if len(problems) > 0 {
+			message = strings.Join(problems, "; ")
+		}
+		checks = append(checks, securityCheck(name, cookie.Name, grade, message))
+	}
+	return checks
+}
+
+// redactCookies copies the response headers with cookie values removed, so
+// session tokens never end up in the database.
+func redactCookies(header http.Header) map[string][]string {
+	headers := make(map[string][]string, len(header))
+	for key, values := range header {
+		if key != "Set-Cookie" {
+			headers[key] = values
+			continue
+		}
+		redacted := make([]string, len(values))
+		for i, value := range values {
+			nameValue, attributes, _ := strings.Cut(value, ";")
+			cookieName, _, _ := strings.Cut(nameValue, "=")
+			redacted[i] = strings.TrimSpace(cookieName) + "=<redacted>"
+			if attributes != "" {
+				redacted[i] += ";" + attributes
+			}
+		}
+		headers[key] = redacted
+	}
+	return headers
+}
@@ -0,0 +1,103 @@
+package services
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"sykell-crawler/internal/models"
+)
+
+func TestAuditSecurityHeaders_Hardened(t *testing.T) {
+	header := http.Header{}
+	header.Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+	header.Set("Strict-Transport-Security", "max-age=63072000; includeSubDomains; preload")
+	header.Set("X-Content-Type-Options", "nosniff")
+	header.Set("Referrer-Policy", "no-referrer-when-downgrade, strict-origin-when-cross-origin")
+	header.Set("Permissions-Policy", "geolocation=()")
+	header.Add("Set-Cookie", "session=secret-token; Path=/; Secure; HttpOnly; SameSite=Lax")
+
+	audit := auditSecurityHeaders(header, true)
+
+	if audit.Warnings != 0 || audit.Failures != 0 {
+		t.Errorf("Expected every check to pass, got %+v", audit.Checks)
+	}
+	if audit.Passed != 7 {
+		t.Errorf("Expected 7 passed checks, got %d", audit.Passed)
+	}
+	if cookies := audit.Headers["Set-Cookie"]; len(cookies) != 1 || strings.Contains(cookies[0], "secret-token") {
+		t.Errorf("Expected cookie values to be redacted, got %v", cookies)
+	}
+}
+
+func TestAuditSecurityHeaders_Grades(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		value  string
+		https  bool
+		grade  string
+	}{
+		{"CSP missing", "Content-Security-Policy", "", true, models.SecurityFail},
+		{"CSP unsafe-inline", "Content-Security-Policy", "script-src 'self' 'unsafe-inline'", true, models.SecurityWarn},
+		{"HSTS missing", "Strict-Transport-Security", "", true, models.SecurityFail},
+		{"HSTS over HTTP", "Strict-Transport-Security", "", false, models.SecurityWarn},
+		{"HSTS short max-age", "Strict-Transport-Security", "max-age=3600", true, models.SecurityWarn},
+		{"HSTS disabled", "Strict-Transport-Security", "max-age=0", true, models.SecurityFail},
+		{"HSTS preload without subdomains", "Strict-Transport-Security", "max-age=63072000; preload", true, models.SecurityWarn},
+		{"X-Frame-Options missing", "X-Frame-Options", "", true, models.SecurityFail},
+		{"X-Frame-Options SAMEORIGIN", "X-Frame-Options", "sameorigin", true, models.SecurityPass},
+		{"X-Frame-Options ALLOW-FROM", "X-Frame-Options", "ALLOW-FROM https://example.com", true, models.SecurityWarn},
+		{"X-Content-Type-Options missing", "X-Content-Type-Options", "", true, models.SecurityFail},
+		{"Referrer-Policy unsafe-url", "Referrer-Policy", "unsafe-url", true, models.SecurityFail},
+		{"Referrer-Policy missing", "Referrer-Policy", "", true, models.SecurityWarn},
+		{"Permissions-Policy missing", "Permissions-Policy", "", true, models.SecurityWarn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			if tt.value != "" {
+				header.Set(tt.header, tt.value)
+			}
+
+			audit := auditSecurityHeaders(header, tt.https)
+			for _, check := range audit.Checks {
+				if check.Header == tt.header {
+					if check.Grade != tt.grade {
+						t.Errorf("Expected grade '%s', got '%s' (%s)", tt.grade, check.Grade, check.Message)
+					}
+					return
+				}
+			}
+			t.Errorf("Expected a check for %s", tt.header)
+		})
+	}
+}
+
+func TestAuditSecurityHeaders_Cookies(t *testing.T) {
+	header := http.Header{}
+	header.Add("Set-Cookie", "prefs=dark; Path=/; Secure; SameSite=Strict")
+	header.Add("Set-Cookie", "session=abc; Path=/; HttpOnly; SameSite=Lax")
+	header.Add("Set-Cookie", "tracker=xyz; SameSite=None")
+
+	audit := auditSecurityHeaders(header, true)
+
+	grades := make(map[string]string)
+	for _, check := range audit.Checks {
+		if check.Header == "Set-Cookie" {
+			grades[check.Value] = check.Grade
+		}
+	}
+
+	expected := map[string]string{
+		"prefs":   models.SecurityWarn,
+		"session": models.SecurityFail,
+		"tracker": models.SecurityFail,
+	}
+	for cookie, grade := range expected {
+		if grades[cookie] != grade {
+			t.Errorf("Expected cookie '%s' to be graded '%s', got '%s'", cookie, grade, grades[cookie])
+		}
+	}
+}
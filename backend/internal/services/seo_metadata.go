@@ -0,0 +1,88 @@
+package services
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+)
+
+// extractSEOMetadata collects the search and social metadata declared in the
+// page head. Meta names are matched case-insensitively; the canonical link is
+// resolved against the page URL so it can be compared with other pages.
+func extractSEOMetadata(doc *goquery.Document, pageURL string) *models.SEOMetadata {
+	seo := &models.SEOMetadata{
+		Lang: strings.TrimSpace(doc.Find("html").First().AttrOr("lang", "")),
+	}
+
+	doc.Find("meta").Each(func(_ int, meta *goquery.Selection) {
+		name := strings.ToLower(strings.TrimSpace(meta.AttrOr("name", "")))
+		property := strings.ToLower(strings.TrimSpace(meta.AttrOr("property", "")))
+		content := strings.TrimSpace(meta.AttrOr("content", ""))
+
+		switch name {
+		case "description":
+			if seo.MetaDescription == "" {
+				seo.MetaDescription = content
+			}
+		case "robots":
+			if seo.MetaRobots == "" {
+				seo.MetaRobots = content
+			}
+		case "viewport":
+			if seo.Viewport == "" {
+				seo.Viewport = content
+			}
+		}
+
+		// Open Graph is specified with property= and Twitter Cards with name=,
+		// but pages mix them up often enough that both are accepted.
+		key := property
+		if key == "" {
+			key = name
+		}
+		switch {
+		case strings.HasPrefix(key, "og:"):
+			seo.OpenGraph = append(seo.OpenGraph, models.MetaTag{Property: key, Content: content})
+		case strings.HasPrefix(key, "twitter:"):
+			seo.TwitterCard = append(seo.TwitterCard, models.MetaTag{Property: key, Content: content})
+		}
+	})
+
+	doc.Find("link[rel][href]").EachWithBreak(func(_ int, link *goquery.Selection) bool {
+		if !hasRel(link, "canonical") {
+			return true
+		}
+		seo.Canonical = resolveURL(pageURL, link.AttrOr("href", ""))
+		return false
+	})
+
+	return seo
+}
+
+// hasRel reports whether the space-separated rel attribute of sel contains
+// value, ignoring case.
+func hasRel(sel *goquery.Selection, value string) bool {
+	for _, rel := range strings.Fields(sel.AttrOr("rel", "")) {
+		if strings.EqualFold(rel, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveURL resolves href against base, returning href unchanged when either
+// cannot be parsed.
+func resolveURL(base, href string) string {
+	href = strings.TrimSpace(href)
+	parsedBase, err := url.Parse(base)
+	if err != nil {
+		return href
+	}
+	parsedHref, err := url.Parse(href)
+	if err != nil {
+		return href
+	}
+	return parsedBase.ResolveReference(parsedHref).String()
+}
@@ -0,0 +1,77 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+)
+
+func TestExtractSEOMetadata(t *testing.T) {
+	html := `<!DOCTYPE html>
+<html lang="en-GB">
+<head>
+	<meta name="Description" content=" A page about crawling. ">
+	<meta name="ROBOTS" content="noindex, follow">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<link rel="alternate canonical" href="/articles/crawling">
+	<meta property="og:title" content="Crawling">
+	<meta property="og:image" content="https://example.com/a.png">
+	<meta name="og:image" content="https://example.com/b.png">
+	<meta name="twitter:card" content="summary_large_image">
+	<meta property="twitter:site" content="@example">
+</head>
+<body></body>
+</html>`
+
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	seo := extractSEOMetadata(doc, "https://example.com/articles/crawling?utm=1")
+
+	if seo.MetaDescription != "A page about crawling." {
+		t.Errorf("Expected trimmed description, got '%s'", seo.MetaDescription)
+	}
+	if seo.MetaRobots != "noindex, follow" {
+		t.Errorf("Expected robots 'noindex, follow', got '%s'", seo.MetaRobots)
+	}
+	if seo.Viewport != "width=device-width, initial-scale=1" {
+		t.Errorf("Unexpected viewport '%s'", seo.Viewport)
+	}
+	if seo.Canonical != "https://example.com/articles/crawling" {
+		t.Errorf("Expected absolute canonical, got '%s'", seo.Canonical)
+	}
+	if seo.Lang != "en-GB" {
+		t.Errorf("Expected lang 'en-GB', got '%s'", seo.Lang)
+	}
+	if len(seo.OpenGraph) != 3 {
+		t.Fatalf("Expected 3 Open Graph tags, got %d", len(seo.OpenGraph))
+	}
+	if seo.OpenGraph[2].Property != "og:image" || seo.OpenGraph[2].Content != "https://example.com/b.png" {
+		t.Errorf("Expected repeated og:image to be kept, got %+v", seo.OpenGraph[2])
+	}
+	if len(seo.TwitterCard) != 2 {
+		t.Fatalf("Expected 2 Twitter Card tags, got %d", len(seo.TwitterCard))
+	}
+	if seo.TwitterCard[0].Property != "twitter:card" || seo.TwitterCard[0].Content != "summary_large_image" {
+		t.Errorf("Unexpected Twitter Card tag %+v", seo.TwitterCard[0])
+	}
+}
+
+func TestExtractSEOMetadata_Empty(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><head><title>Bare</title></head></html>`))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+
+	seo := extractSEOMetadata(doc, "https://example.com/")
+
+	if seo.MetaDescription != "" || seo.Canonical != "" || seo.Lang != "" {
+		t.Errorf("Expected empty metadata, got %+v", seo)
+	}
+	if len(seo.OpenGraph) != 0 || len(seo.TwitterCard) != 0 {
+		t.Errorf("Expected no social tags, got %+v", seo)
+	}
+}
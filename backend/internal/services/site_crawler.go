@@ -0,0 +1,171 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"sykell-crawler/internal/models"
+)
+
+type frontierEntry struct {
+	URL   string
+	Depth int
+}
+
+// crawlFrontier is the breadth-first queue of pages still to visit during a
+// site crawl. It only accepts http(s) pages within the site's scope and never
+// hands out the same page twice. Pages are also tracked by the URL they were
+// finally served from, so links that redirect to the same page are crawled
+// once.
+type crawlFrontier struct {
+	scope   *siteScope
+	entries []frontierEntry
+	seen    map[string]bool
+	crawled map[string]bool
+}
+
+func newCrawlFrontier(seed *url.URL, scope *siteScope) *crawlFrontier {
+	f := &crawlFrontier{
+		scope:   scope,
+		seen:    make(map[string]bool),
+		crawled: make(map[string]bool),
+	}
+	key := normalizeFrontierURL(seed)
+	f.seen[key] = true
+	f.crawled[key] = true
+	return f
+}
+
+func (f *crawlFrontier) push(links []string, depth int) {
+	for _, link := range links {
+		parsed, err := url.Parse(link)
+		if err != nil {
+			continue
+		}
+		if parsed.Scheme != "http" && parsed.Scheme != "https" {
+			continue
+		}
+		if !f.scope.contains(parsed) {
+			continue
+		}
+
+		key := normalizeFrontierURL(parsed)
+		if f.seen[key] {
+			continue
+		}
+		f.seen[key] = true
+		f.entries = append(f.entries, frontierEntry{URL: key, Depth: depth})
+	}
+}
+
+// pop returns the next page to visit, skipping pages that an earlier link
+// already redirected to.
+func (f *crawlFrontier) pop() (frontierEntry, bool) {
+	for len(f.entries) > 0 {
+		entry := f.entries[0]
+		f.entries = f.entries[1:]
+		if !f.crawled[entry.URL] {
+			return entry, true
+		}
+	}
+	return frontierEntry{}, false
+}
+
+// markCrawled records the URL a page was finally served from and reports
+// whether it is new, i.e. no other link already led to the same page.
+func (f *crawlFrontier) markCrawled(finalURL string) bool {
+	parsed, err := url.Parse(finalURL)
+	if finalURL == "" || err != nil {
+		return true
+	}
+	key := normalizeFrontierURL(parsed)
+	if f.crawled[key] {
+		return false
+	}
+	f.crawled[key] = true
+	f.seen[key] = true
+	return true
+}
+
+// normalizeFrontierURL drops the fragment and lower-cases the host so that
+// trivially different spellings of the same page are deduplicated.
+func normalizeFrontierURL(u *url.URL) string {
+	normalized := *u
+	normalized.Fragment = ""
+	normalized.RawFragment = ""
+	normalized.Host = strings.ToLower(normalized.Host)
+	if normalized.Path == "" {
+		normalized.Path = "/"
+	}
+	return normalized.String()
+}
+
+// crawlSitePages follows internal links breadth-first from the seed page and
+// returns one result per discovered page, within the job's depth and page
+// limits. The seed page itself counts towards the page limit. seedURL is the
+// URL the seed page was finally served from, which anchors the site's scope.
+func (s *crawlerService) crawlSitePages(ctx context.Context, job CrawlJob, urlModel *models.URL, seedURL string, seedLinks []string) []models.CrawlResult {
+	seed, err := url.Parse(seedURL)
+	if err != nil {
+		return nil
+	}
+
+	maxDepth, maxPages := s.siteCrawlLimits(job)
+	if maxDepth < 1 || maxPages < 2 {
+		return nil
+	}
+
+	scope := newSiteScope(seed, urlModel.ScopeMode, urlModel.ScopeHosts)
+	frontier := newCrawlFrontier(seed, scope)
+	frontier.push(seedLinks, 1)
+
+	var pages []models.CrawlResult
+	for len(pages)+1 < maxPages {
+		if ctx.Err() != nil {
+			break
+		}
+		if cancelled, err := s.queue.IsCancelled(job.URLID); err == nil && cancelled {
+			break
+		}
+
+		entry, ok := frontier.pop()
+		if !ok {
+			break
+		}
+
+		result, links, err := s.performCrawl(entry.URL, urlModel, scope)
+		if err != nil {
+			result = failedResult(err)
+		}
+		if !frontier.markCrawled(result.FinalURL) {
+			// Redirected to a page that was already crawled
+			continue
+		}
+		result.URLID = job.URLID
+		result.PageURL = entry.URL
+		result.Depth = entry.Depth
+		pages = append(pages, *result)
+
+		if entry.Depth < maxDepth {
+			frontier.push(links, entry.Depth+1)
+		}
+	}
+
+	return pages
+}
+
+// siteCrawlLimits resolves the job's depth and page limits, falling back to
+// and capping at the configured maximums.
+func (s *crawlerService) siteCrawlLimits(job CrawlJob) (maxDepth, maxPages int) {
+	maxDepth = job.MaxDepth
+	if maxDepth <= 0 || (s.config.SiteCrawlMaxDepth > 0 && maxDepth > s.config.SiteCrawlMaxDepth) {
+		maxDepth = s.config.SiteCrawlMaxDepth
+	}
+
+	maxPages = job.MaxPages
+	if maxPages <= 0 || (s.config.SiteCrawlMaxPages > 0 && maxPages > s.config.SiteCrawlMaxPages) {
+		maxPages = s.config.SiteCrawlMaxPages
+	}
+
+	return maxDepth, maxPages
+}
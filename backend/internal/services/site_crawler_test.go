@@ -0,0 +1,160 @@
+package services
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sykell-crawler/internal/models"
+	"testing"
+)
+
+func newSiteTestServer() *httptest.Server {
+	pages := map[string]string{
+		"/":       `<html><head><title>Home</title></head><body><a href="/a">A</a><a href="/b#top">B</a><a href="/">Home</a><a href="mailto:x@example.com">Mail</a></body></html>`,
+		"/a":      `<html><head><title>A</title></head><body><a href="/a/deep">Deep</a><a href="/b">B</a></body></html>`,
+		"/b":      `<html><head><title>B</title></head><body><a href="/">Home</a></body></html>`,
+		"/a/deep": `<html><head><title>Deep</title></head><body><a href="/a/deeper">Deeper</a></body></html>`,
+	}
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, exists := pages[r.URL.Path]
+		if !exists {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+}
+
+func TestCrawlSite_FollowsInternalLinksWithinDepth(t *testing.T) {
+	server := newSiteTestServer()
+	defer server.Close()
+
+	urlRepo := &mockURLRepository{
+		urls: map[uint]*models.URL{
+			1: {ID: 1, URL: server.URL, Status: models.StatusQueued, CrawlMode: models.CrawlModeSite},
+		},
+	}
+	resultRepo := &mockCrawlResultRepository{
+		results: make(map[uint]*models.CrawlResult),
+	}
+	cfg := createTestConfig()
+	cfg.SiteCrawlMaxDepth = 3
+	cfg.SiteCrawlMaxPages = 50
+
+	service := NewCrawlerService(urlRepo, resultRepo, nil, &mockQueueService{}, cfg)
+	err := service.CrawlSite(context.Background(), CrawlJob{URLID: 1, MaxDepth: 1})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	result := resultRepo.results[1]
+	if result == nil {
+		t.Fatal("Expected crawl result to be saved")
+	}
+
+	// Depth 1 reaches /a and /b but not /a/deep
+	if len(result.Pages) != 2 {
+		t.Fatalf("Expected 2 child pages, got %d", len(result.Pages))
+	}
+	if result.PagesCrawled != 3 {
+		t.Errorf("Expected 3 pages crawled, got %d", result.PagesCrawled)
+	}
+	if result.Pages[0].Title != "A" || result.Pages[1].Title != "B" {
+		t.Errorf("Expected pages A and B in discovery order, got '%s' and '%s'", result.Pages[0].Title, result.Pages[1].Title)
+	}
+	for _, page := range result.Pages {
+		if page.URLID != 1 {
+			t.Errorf("Expected child page to belong to URL 1, got %d", page.URLID)
+		}
+		if page.Depth != 1 {
+			t.Errorf("Expected child page depth 1, got %d", page.Depth)
+		}
+	}
+}
+
+func TestCrawlSite_RespectsMaxPages(t *testing.T) {
+	server := newSiteTestServer()
+	defer server.Close()
+
+	urlRepo := &mockURLRepository{
+		urls: map[uint]*models.URL{
+			1: {ID: 1, URL: server.URL, Status: models.StatusQueued, CrawlMode: models.CrawlModeSite},
+		},
+	}
+	resultRepo := &mockCrawlResultRepository{
+		results: make(map[uint]*models.CrawlResult),
+	}
+	cfg := createTestConfig()
+	cfg.SiteCrawlMaxDepth = 5
+	cfg.SiteCrawlMaxPages = 2
+
+	service := NewCrawlerService(urlRepo, resultRepo, nil, &mockQueueService{}, cfg)
+	if err := service.CrawlSite(context.Background(), CrawlJob{URLID: 1, MaxPages: 10}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	result := resultRepo.results[1]
+	if result.PagesCrawled != 2 {
+		t.Errorf("Expected page limit to be capped at 2, got %d", result.PagesCrawled)
+	}
+}
+
+func TestCrawlFrontier_Dedup(t *testing.T) {
+	seed, _ := url.Parse("https://example.com")
+	frontier := newCrawlFrontier(seed, newSiteScope(seed, models.ScopeHost, nil))
+
+	frontier.push([]string{
+		"https://example.com/",
+		"https://EXAMPLE.com/page#section",
+		"https://example.com/page",
+		"https://other.com/page",
+		"mailto:someone@example.com",
+	}, 1)
+
+	entry, ok := frontier.pop()
+	if !ok || entry.URL != "https://example.com/page" {
+		t.Errorf("Expected 'https://example.com/page', got '%s'", entry.URL)
+	}
+	if _, ok := frontier.pop(); ok {
+		t.Error("Expected frontier to be empty after deduplication")
+	}
+}
+
+func TestCrawlSite_SkipsPagesRedirectingToCrawledPages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/":
+			w.Write([]byte(`<html><body><a href="/a">A</a><a href="/a/">A again</a><a href="/home">Home</a></body></html>`))
+		case "/a/":
+			http.Redirect(w, r, "/a", http.StatusMovedPermanently)
+		case "/home":
+			http.Redirect(w, r, "/", http.StatusFound)
+		default:
+			w.Write([]byte(`<html><head><title>A</title></head><body></body></html>`))
+		}
+	}))
+	defer server.Close()
+
+	urlRepo := &mockURLRepository{
+		urls: map[uint]*models.URL{
+			1: {ID: 1, URL: server.URL, Status: models.StatusQueued, CrawlMode: models.CrawlModeSite},
+		},
+	}
+	resultRepo := &mockCrawlResultRepository{
+		results: make(map[uint]*models.CrawlResult),
+	}
+	cfg := createTestConfig()
+	cfg.SiteCrawlMaxDepth = 3
+	cfg.SiteCrawlMaxPages = 50
+
+	service := NewCrawlerService(urlRepo, resultRepo, nil, &mockQueueService{}, cfg)
+	if err := service.CrawlSite(context.Background(), CrawlJob{URLID: 1}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	result := resultRepo.results[1]
+	if len(result.Pages) != 1 || result.Pages[0].Title != "A" {
+		t.Errorf("Expected /a to be stored once and the redirect to the seed to be skipped, got %d pages", len(result.Pages))
+	}
+}
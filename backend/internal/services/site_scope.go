@@ -0,0 +1,92 @@
+package services
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/publicsuffix"
+	"sykell-crawler/internal/models"
+)
+
+// siteScope decides which hosts belong to the crawled site. Links to them
+// count as internal and site crawls follow them.
+type siteScope struct {
+	mode models.ScopeMode
+	// host is the site's hostname without a leading "www."
+	host string
+	// domain is the registrable domain of the site, e.g. example.co.uk, or
+	// empty when it has none, as for IP addresses and localhost
+	domain string
+	hosts  []string
+}
+
+// newSiteScope anchors the URL's scope rules at the host of site, the URL
+// the crawl's first page was served from.
+func newSiteScope(site *url.URL, mode models.ScopeMode, hosts []string) *siteScope {
+	hostname := scopeHostname(site)
+	scope := &siteScope{
+		mode:  mode,
+		host:  strings.TrimPrefix(hostname, "www."),
+		hosts: hosts,
+	}
+	if net.ParseIP(hostname) == nil {
+		if domain, err := publicsuffix.EffectiveTLDPlusOne(hostname); err == nil {
+			scope.domain = domain
+		}
+	}
+	return scope
+}
+
+// contains reports whether u is on one of the site's hosts. The www. prefix
+// is ignored, so www.example.com and example.com are the same host.
+func (sc *siteScope) contains(u *url.URL) bool {
+	hostname := scopeHostname(u)
+	if hostname == "" {
+		return false
+	}
+	for _, pattern := range sc.hosts {
+		if matchScopeHost(hostname, pattern) {
+			return true
+		}
+	}
+
+	switch sc.mode {
+	case models.ScopeDomain:
+		if sc.domain != "" {
+			return hostname == sc.domain || strings.HasSuffix(hostname, "."+sc.domain)
+		}
+	case models.ScopeSubdomains:
+		return hostname == sc.host || strings.HasSuffix(hostname, "."+sc.host)
+	}
+	return strings.TrimPrefix(hostname, "www.") == sc.host
+}
+
+func scopeHostname(u *url.URL) string {
+	return strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+}
+
+// matchScopeHost matches a hostname against an extra scope host, which is
+// either a hostname or "*." followed by a domain to include it and all of its
+// subdomains.
+func matchScopeHost(hostname, pattern string) bool {
+	if domain, ok := strings.CutPrefix(pattern, "*."); ok {
+		return hostname == domain || strings.HasSuffix(hostname, "."+domain)
+	}
+	return hostname == pattern
+}
+
+// normalizeScopeHosts validates extra scope hosts and lower-cases them.
+func normalizeScopeHosts(hosts []string) ([]string, error) {
+	normalized := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		host = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(host)), ".")
+		name := strings.TrimPrefix(host, "*.")
+		if name == "" || strings.ContainsAny(name, "/:*@ ") {
+			return nil, fmt.Errorf("invalid scope host %q", host)
+		}
+		normalized = append(normalized, host)
+	}
+	return normalized, nil
+}
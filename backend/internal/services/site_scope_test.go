@@ -0,0 +1,61 @@
+package services
+
+import (
+	"net/url"
+	"testing"
+
+	"sykell-crawler/internal/models"
+)
+
+func TestSiteScope_Contains(t *testing.T) {
+	tests := []struct {
+		name     string
+		site     string
+		mode     models.ScopeMode
+		hosts    []string
+		link     string
+		expected bool
+	}{
+		{"same host", "https://example.com/", models.ScopeHost, nil, "http://example.com/a", true},
+		{"www is the same host", "https://example.com/", models.ScopeHost, nil, "https://www.example.com/a", true},
+		{"host ignores case and port", "https://www.example.com/", models.ScopeHost, nil, "https://EXAMPLE.com:8443/a", true},
+		{"host excludes subdomains", "https://example.com/", models.ScopeHost, nil, "https://blog.example.com/", false},
+		{"subdomains", "https://www.example.com/", models.ScopeSubdomains, nil, "https://blog.example.com/", true},
+		{"subdomains exclude the parent", "https://shop.example.com/", models.ScopeSubdomains, nil, "https://example.com/", false},
+		{"domain includes siblings", "https://shop.example.co.uk/", models.ScopeDomain, nil, "https://blog.example.co.uk/", true},
+		{"domain stops at the public suffix", "https://shop.example.co.uk/", models.ScopeDomain, nil, "https://other.co.uk/", false},
+		{"domain on an IP address", "http://127.0.0.1:8080/", models.ScopeDomain, nil, "http://127.0.0.1/", true},
+		{"extra host", "https://example.com/", models.ScopeHost, []string{"cdn.example.net"}, "https://cdn.example.net/x", true},
+		{"extra host is exact", "https://example.com/", models.ScopeHost, []string{"cdn.example.net"}, "https://img.cdn.example.net/x", false},
+		{"extra wildcard host", "https://example.com/", models.ScopeHost, []string{"*.example.net"}, "https://img.cdn.example.net/x", true},
+		{"other site", "https://example.com/", models.ScopeDomain, nil, "https://example.org/", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site, _ := url.Parse(tt.site)
+			link, _ := url.Parse(tt.link)
+			scope := newSiteScope(site, tt.mode, tt.hosts)
+
+			if got := scope.contains(link); got != tt.expected {
+				t.Errorf("Expected contains(%s) to be %t, got %t", tt.link, tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestNormalizeScopeHosts(t *testing.T) {
+	hosts, err := normalizeScopeHosts([]string{" CDN.Example.net. ", "*.Example.org"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(hosts) != 2 || hosts[0] != "cdn.example.net" || hosts[1] != "*.example.org" {
+		t.Errorf("Unexpected hosts %v", hosts)
+	}
+
+	for _, invalid := range []string{"", "https://example.com", "example.com:8080", "a.*.example.com"} {
+		if _, err := normalizeScopeHosts([]string{invalid}); err == nil {
+			t.Errorf("Expected %q to be rejected", invalid)
+		}
+	}
+}
@@ -0,0 +1,282 @@
+package services
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sykell-crawler/pkg/config"
+)
+
+// Limits from the sitemaps.org protocol, plus a cap on how deep indexes may nest.
+const (
+	maxSitemapSize       = 50 * 1024 * 1024
+	maxSitemapIndexDepth = 3
+	maxSitemapFiles      = 100
+)
+
+var ErrNoSitemap = errors.New("no sitemap found")
+
+type SitemapEntry struct {
+	URL      string   `json:"url"`
+	LastMod  string   `json:"lastmod,omitempty"`
+	Priority *float64 `json:"priority,omitempty"`
+	Outcome  string   `json:"outcome"`
+	Error    string   `json:"error,omitempty"`
+}
+
+const (
+	SitemapOutcomeAdded    = "added"
+	SitemapOutcomeRestored = "restored"
+	SitemapOutcomeExisting = "existing"
+	SitemapOutcomeFailed   = "failed"
+	SitemapOutcomeSkipped  = "skipped"
+)
+
+type SitemapImportResult struct {
+	Sitemaps        []string       `json:"sitemaps"`
+	SkippedSitemaps []string       `json:"skipped_sitemaps,omitempty"`
+	Added           int            `json:"added"`
+	Restored        int            `json:"restored"`
+	Existing        int            `json:"existing"`
+	Failed          int            `json:"failed"`
+	Skipped         int            `json:"skipped"`
+	Entries         []SitemapEntry `json:"entries"`
+}
+
+type SitemapService interface {
+	ImportSitemaps(domain string) (*SitemapImportResult, error)
+}
+
+type sitemapService struct {
+	urlService URLService
+	client     *http.Client
+	config     *config.Config
+}
+
+func NewSitemapService(urlService URLService, cfg *config.Config) SitemapService {
+	return &sitemapService{
+		urlService: urlService,
+		config:     cfg,
+		client: &http.Client{
+			Timeout:   cfg.HTTPTimeout,
+			Transport: &userAgentTransport{base: http.DefaultTransport, userAgent: cfg.RobotsUserAgent},
+		},
+	}
+}
+
+// sitemapDocument covers both <urlset> and <sitemapindex> roots.
+type sitemapDocument struct {
+	XMLName  xml.Name
+	URLs     []sitemapURL `xml:"url"`
+	Sitemaps []sitemapRef `xml:"sitemap"`
+}
+
+type sitemapURL struct {
+	Loc      string `xml:"loc"`
+	LastMod  string `xml:"lastmod"`
+	Priority string `xml:"priority"`
+}
+
+type sitemapRef struct {
+	Loc string `xml:"loc"`
+}
+
+// ImportSitemaps discovers the domain's sitemaps through robots.txt and
+// /sitemap.xml, walks any sitemap indexes, and adds every listed page the
+// same way a single URL is added. As the sitemaps protocol requires, only
+// child sitemaps and pages on the domain's own host are followed; others are
+// reported as skipped.
+func (s *sitemapService) ImportSitemaps(domain string) (*SitemapImportResult, error) {
+	base, err := sitemapBaseURL(domain)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &SitemapImportResult{}
+	seenSitemaps := make(map[string]bool)
+	seenURLs := make(map[string]bool)
+
+	type pendingSitemap struct {
+		loc   string
+		depth int
+	}
+
+	// Walk depth-first so entries keep the order they're listed in
+	discovered := s.discoverSitemaps(base)
+	var stack []pendingSitemap
+	for i := len(discovered) - 1; i >= 0; i-- {
+		stack = append(stack, pendingSitemap{loc: discovered[i]})
+	}
+
+	var entries []sitemapURL
+	for len(stack) > 0 && len(seenSitemaps) < maxSitemapFiles {
+		next := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if seenSitemaps[next.loc] {
+			continue
+		}
+		seenSitemaps[next.loc] = true
+
+		doc, err := s.fetchSitemap(next.loc)
+		if err != nil {
+			continue
+		}
+		result.Sitemaps = append(result.Sitemaps, next.loc)
+
+		if next.depth < maxSitemapIndexDepth {
+			for i := len(doc.Sitemaps) - 1; i >= 0; i-- {
+				loc := strings.TrimSpace(doc.Sitemaps[i].Loc)
+				switch {
+				case loc == "":
+				case !onSitemapHost(loc, base):
+					result.SkippedSitemaps = append(result.SkippedSitemaps, loc)
+				default:
+					stack = append(stack, pendingSitemap{loc: loc, depth: next.depth + 1})
+				}
+			}
+		}
+		entries = append(entries, doc.URLs...)
+	}
+
+	if len(result.Sitemaps) == 0 {
+		return nil, ErrNoSitemap
+	}
+
+	for _, entry := range entries {
+		loc := strings.TrimSpace(entry.Loc)
+		if loc == "" || seenURLs[loc] {
+			continue
+		}
+		if s.config.SitemapMaxURLs > 0 && len(seenURLs) >= s.config.SitemapMaxURLs {
+			break
+		}
+		seenURLs[loc] = true
+
+		if parsed, err := url.Parse(loc); err == nil && parsed.Host != "" && !strings.EqualFold(parsed.Host, base.Host) {
+			result.Entries = append(result.Entries, SitemapEntry{
+				URL:     loc,
+				Outcome: SitemapOutcomeSkipped,
+				Error:   fmt.Sprintf("not on %s", base.Host),
+			})
+			result.Skipped++
+			continue
+		}
+		result.Entries = append(result.Entries, s.importEntry(loc, entry, result))
+	}
+
+	return result, nil
+}
+
+func (s *sitemapService) importEntry(loc string, entry sitemapURL, result *SitemapImportResult) SitemapEntry {
+	imported := SitemapEntry{
+		URL:     loc,
+		LastMod: strings.TrimSpace(entry.LastMod),
+	}
+	if priority, err := strconv.ParseFloat(strings.TrimSpace(entry.Priority), 64); err == nil {
+		imported.Priority = &priority
+	}
+
+	added, err := s.urlService.AddURL(loc, AddURLOptions{})
+	switch {
+	case err != nil:
+		imported.Outcome = SitemapOutcomeFailed
+		imported.Error = err.Error()
+		result.Failed++
+	case added.IsNew:
+		imported.Outcome = SitemapOutcomeAdded
+		result.Added++
+	case added.IsRestored:
+		imported.Outcome = SitemapOutcomeRestored
+		result.Restored++
+	default:
+		imported.Outcome = SitemapOutcomeExisting
+		result.Existing++
+	}
+
+	return imported
+}
+
+// discoverSitemaps lists the sitemaps declared in robots.txt, falling back to
+// the conventional /sitemap.xml location when it declares none.
+func (s *sitemapService) discoverSitemaps(base *url.URL) []string {
+	var sitemaps []string
+
+	if resp, err := s.client.Get(base.String() + "/robots.txt"); err == nil {
+		if resp.StatusCode < 300 {
+			sitemaps = append(sitemaps, parseRobots(resp.Body).sitemaps...)
+		}
+		resp.Body.Close()
+	}
+
+	if len(sitemaps) == 0 {
+		return []string{base.String() + "/sitemap.xml"}
+	}
+	return sitemaps
+}
+
+func (s *sitemapService) fetchSitemap(loc string) (*sitemapDocument, error) {
+	resp, err := s.client.Get(loc)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("HTTP error: %d", resp.StatusCode)
+	}
+
+	return parseSitemap(resp.Body)
+}
+
+// parseSitemap decodes a sitemap or sitemap index, transparently handling
+// gzip-compressed files.
+func parseSitemap(r io.Reader) (*sitemapDocument, error) {
+	buffered := bufio.NewReader(r)
+	if magic, err := buffered.Peek(2); err == nil && magic[0] == 0x1f && magic[1] == 0x8b {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return nil, err
+		}
+		defer gz.Close()
+		r = gz
+	} else {
+		r = buffered
+	}
+
+	var doc sitemapDocument
+	if err := xml.NewDecoder(io.LimitReader(r, maxSitemapSize)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to parse sitemap: %w", err)
+	}
+	if doc.XMLName.Local != "urlset" && doc.XMLName.Local != "sitemapindex" {
+		return nil, fmt.Errorf("unexpected sitemap root element <%s>", doc.XMLName.Local)
+	}
+
+	return &doc, nil
+}
+
+// onSitemapHost reports whether loc is on the host of the imported domain.
+func onSitemapHost(loc string, base *url.URL) bool {
+	parsed, err := url.Parse(loc)
+	return err == nil && strings.EqualFold(parsed.Host, base.Host)
+}
+
+func sitemapBaseURL(domain string) (*url.URL, error) {
+	domain = strings.TrimSpace(domain)
+	if !strings.HasPrefix(domain, "http://") && !strings.HasPrefix(domain, "https://") {
+		domain = "https://" + domain
+	}
+
+	parsed, err := url.Parse(domain)
+	if err != nil || parsed.Host == "" {
+		return nil, errors.New("invalid domain")
+	}
+
+	return &url.URL{Scheme: parsed.Scheme, Host: parsed.Host}, nil
+}
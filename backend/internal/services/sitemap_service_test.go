@@ -0,0 +1,192 @@
+package services
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sykell-crawler/internal/models"
+	"testing"
+)
+
+type mockSitemapURLService struct {
+	URLService
+	existing map[string]bool
+	deleted  map[string]bool
+	added    []string
+}
+
+func (m *mockSitemapURLService) AddURL(urlStr string, opts AddURLOptions) (*AddURLResult, error) {
+	if !strings.HasPrefix(urlStr, "http") {
+		return nil, errors.New("invalid URL format")
+	}
+	m.added = append(m.added, urlStr)
+	switch {
+	case m.existing[urlStr]:
+		return &AddURLResult{URL: &models.URL{URL: urlStr}}, nil
+	case m.deleted[urlStr]:
+		return &AddURLResult{URL: &models.URL{URL: urlStr}, IsRestored: true}, nil
+	}
+	return &AddURLResult{URL: &models.URL{URL: urlStr}, IsNew: true}, nil
+}
+
+func gzipBytes(t *testing.T, data string) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write([]byte(data)); err != nil {
+		t.Fatalf("Failed to gzip: %v", err)
+	}
+	gz.Close()
+	return buf.Bytes()
+}
+
+func TestImportSitemaps_IndexAndGzip(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			w.Write([]byte("User-agent: *\nDisallow:\nSitemap: " + server.URL + "/sitemap_index.xml\n"))
+		case "/sitemap_index.xml":
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>` + server.URL + `/pages.xml.gz</loc></sitemap>
+  <sitemap><loc>` + server.URL + `/posts.xml</loc></sitemap>
+</sitemapindex>`))
+		case "/pages.xml.gz":
+			w.Write(gzipBytes(t, `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>`+server.URL+`/</loc><lastmod>2024-01-01</lastmod><priority>1.0</priority></url>
+  <url><loc>`+server.URL+`/about</loc><priority>0.5</priority></url>
+</urlset>`))
+		case "/posts.xml":
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <url><loc>` + server.URL + `/about</loc></url>
+  <url><loc>` + server.URL + `/old-post</loc></url>
+  <url><loc>` + server.URL + `/deleted-post</loc></url>
+  <url><loc>not a url</loc></url>
+</urlset>`))
+		case "/sitemap.xml":
+			t.Error("Expected /sitemap.xml not to be fetched when robots.txt lists sitemaps")
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	urlService := &mockSitemapURLService{
+		existing: map[string]bool{server.URL + "/old-post": true},
+		deleted:  map[string]bool{server.URL + "/deleted-post": true},
+	}
+	cfg := createTestConfig()
+	cfg.SitemapMaxURLs = 100
+	service := NewSitemapService(urlService, cfg)
+
+	result, err := service.ImportSitemaps(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	// The index and both child sitemaps; /sitemap.xml isn't tried since robots.txt lists one
+	if len(result.Sitemaps) != 3 {
+		t.Errorf("Expected 3 sitemaps to be read, got %v", result.Sitemaps)
+	}
+	if result.Added != 2 || result.Existing != 1 || result.Restored != 1 || result.Failed != 1 {
+		t.Errorf("Expected 2 added, 1 existing, 1 restored, 1 failed, got %d/%d/%d/%d",
+			result.Added, result.Existing, result.Restored, result.Failed)
+	}
+	if len(result.Entries) != 5 {
+		t.Fatalf("Expected 5 unique entries, got %d", len(result.Entries))
+	}
+
+	first := result.Entries[0]
+	if first.URL != server.URL+"/" || first.LastMod != "2024-01-01" {
+		t.Errorf("Expected first entry with lastmod, got %+v", first)
+	}
+	if first.Priority == nil || *first.Priority != 1.0 {
+		t.Errorf("Expected priority 1.0, got %v", first.Priority)
+	}
+}
+
+func TestImportSitemaps_FallbackSitemapXML(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/sitemap.xml" {
+			w.Write([]byte(`<urlset><url><loc>` + server.URL + `/page</loc></url></urlset>`))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	urlService := &mockSitemapURLService{}
+	service := NewSitemapService(urlService, createTestConfig())
+
+	result, err := service.ImportSitemaps(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if result.Added != 1 {
+		t.Errorf("Expected 1 URL added, got %d", result.Added)
+	}
+}
+
+func TestImportSitemaps_SkipsOtherHosts(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("User-Agent") != "sykell-crawler" {
+			t.Errorf("Expected the robots User-Agent, got %q", r.Header.Get("User-Agent"))
+		}
+		switch r.URL.Path {
+		case "/sitemap.xml":
+			w.Write([]byte(`<sitemapindex>
+  <sitemap><loc>http://169.254.169.254/latest/meta-data</loc></sitemap>
+  <sitemap><loc>` + server.URL + `/pages.xml</loc></sitemap>
+</sitemapindex>`))
+		case "/pages.xml":
+			w.Write([]byte(`<urlset>
+  <url><loc>` + server.URL + `/page</loc></url>
+  <url><loc>https://other.example.com/page</loc></url>
+</urlset>`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	urlService := &mockSitemapURLService{}
+	cfg := createTestConfig()
+	cfg.RobotsUserAgent = "sykell-crawler"
+	service := NewSitemapService(urlService, cfg)
+
+	result, err := service.ImportSitemaps(server.URL)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(result.SkippedSitemaps) != 1 || len(result.Sitemaps) != 2 {
+		t.Errorf("Expected the sitemap on another host to be skipped, got %v and %v", result.Sitemaps, result.SkippedSitemaps)
+	}
+	if result.Added != 1 || result.Skipped != 1 || len(urlService.added) != 1 {
+		t.Errorf("Expected the page on another host to be skipped, got %+v", result.Entries)
+	}
+	if entry := result.Entries[1]; entry.Outcome != SitemapOutcomeSkipped {
+		t.Errorf("Expected a skipped entry, got %+v", entry)
+	}
+}
+
+func TestImportSitemaps_NoSitemap(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	service := NewSitemapService(&mockSitemapURLService{}, createTestConfig())
+
+	_, err := service.ImportSitemaps(server.URL)
+	if !errors.Is(err, ErrNoSitemap) {
+		t.Errorf("Expected ErrNoSitemap, got %v", err)
+	}
+}
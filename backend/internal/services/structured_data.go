@@ -0,0 +1,375 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+)
+
+func init() {
+	RegisterAnalyzer(structuredDataAnalyzer{})
+}
+
+// structuredDataAnalyzer fills the structured data of the crawl result.
+type structuredDataAnalyzer struct{}
+
+func (structuredDataAnalyzer) Name() string {
+	return "structured-data"
+}
+
+func (structuredDataAnalyzer) Description() string {
+	return "Extracts and validates JSON-LD, microdata and RDFa items"
+}
+
+func (structuredDataAnalyzer) Analyze(input *AnalyzerInput) ([]models.Finding, error) {
+	input.Result.StructuredData = extractStructuredData(input.Document)
+	return nil, nil
+}
+
+// maxStructuredDataItems caps how many items are stored per page so a page
+// generating thousands of itemscopes cannot bloat the result.
+const maxStructuredDataItems = 100
+
+// maxStructuredDataTypeLength matches the size of the type column, since a
+// page can declare any type it likes.
+const maxStructuredDataTypeLength = 128
+
+// structuredDataRequirement lists properties of which at least one must be set.
+type structuredDataRequirement []string
+
+var articleRequirements = []structuredDataRequirement{
+	{"headline"},
+	{"author"},
+	{"datePublished"},
+	{"image"},
+}
+
+// structuredDataRequirements holds the required properties for schema.org
+// types that produce rich results.
+var structuredDataRequirements = map[string][]structuredDataRequirement{
+	"Product": {
+		{"name"},
+		{"offers", "review", "aggregateRating"},
+	},
+	"Article":        articleRequirements,
+	"NewsArticle":    articleRequirements,
+	"BlogPosting":    articleRequirements,
+	"BreadcrumbList": {{"itemListElement"}},
+}
+
+// extractStructuredData collects the top-level JSON-LD, Microdata and RDFa
+// items on a page and validates each against structuredDataRequirements.
+func extractStructuredData(doc *goquery.Document) []models.StructuredDataItem {
+	var items []models.StructuredDataItem
+
+	doc.Find("script[type]").Each(func(_ int, script *goquery.Selection) {
+		scriptType := strings.ToLower(strings.TrimSpace(script.AttrOr("type", "")))
+		if scriptType != "application/ld+json" {
+			return
+		}
+		items = append(items, parseJSONLD(script.Text())...)
+	})
+
+	for _, props := range collectItems(doc.Selection, "itemscope", "itemprop", microdataItem) {
+		items = append(items, newStructuredDataItem(models.StructuredDataMicrodata, props))
+	}
+	for _, props := range collectItems(doc.Selection, "typeof", "property", rdfaItem) {
+		items = append(items, newStructuredDataItem(models.StructuredDataRDFa, props))
+	}
+
+	if len(items) > maxStructuredDataItems {
+		items = items[:maxStructuredDataItems]
+	}
+	return items
+}
+
+// parseJSONLD turns a JSON-LD script body into items. A body may hold a single
+// object, an array of objects or an object with an @graph.
+func parseJSONLD(body string) []models.StructuredDataItem {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return nil
+	}
+
+	var data interface{}
+	if err := json.Unmarshal([]byte(body), &data); err != nil {
+		return []models.StructuredDataItem{{
+			Format: models.StructuredDataJSONLD,
+			Errors: []string{fmt.Sprintf("invalid JSON: %v", err)},
+		}}
+	}
+
+	var objects []map[string]interface{}
+	switch value := data.(type) {
+	case map[string]interface{}:
+		if graph, ok := value["@graph"].([]interface{}); ok {
+			objects = appendObjects(objects, graph)
+		} else {
+			objects = append(objects, value)
+		}
+	case []interface{}:
+		objects = appendObjects(objects, value)
+	}
+
+	var items []models.StructuredDataItem
+	for _, object := range objects {
+		items = append(items, newStructuredDataItem(models.StructuredDataJSONLD, object))
+	}
+	return items
+}
+
+func appendObjects(objects []map[string]interface{}, values []interface{}) []map[string]interface{} {
+	for _, value := range values {
+		if object, ok := value.(map[string]interface{}); ok {
+			objects = append(objects, object)
+		}
+	}
+	return objects
+}
+
+func newStructuredDataItem(format string, props map[string]interface{}) models.StructuredDataItem {
+	types := structuredDataTypes(props["@type"])
+	item := models.StructuredDataItem{
+		Format:     format,
+		Properties: props,
+	}
+	if len(types) == 0 {
+		item.Errors = []string{"missing @type"}
+	} else {
+		item.Type = truncateRunes(types[0], maxStructuredDataTypeLength)
+		item.Errors = validateStructuredData(types, props)
+	}
+	item.Valid = len(item.Errors) == 0
+	return item
+}
+
+// structuredDataTypes returns the vocabulary terms of an @type value, which
+// may be a string or a list of strings.
+func structuredDataTypes(value interface{}) []string {
+	var types []string
+	switch t := value.(type) {
+	case string:
+		for _, field := range strings.Fields(t) {
+			types = append(types, schemaTerm(field))
+		}
+	case []interface{}:
+		for _, v := range t {
+			if s, ok := v.(string); ok && strings.TrimSpace(s) != "" {
+				types = append(types, schemaTerm(strings.TrimSpace(s)))
+			}
+		}
+	}
+	return types
+}
+
+// schemaTerm strips the vocabulary from a type or property reference, so that
+// "https://schema.org/Product" and "schema:Product" both become "Product".
+func schemaTerm(ref string) string {
+	if i := strings.LastIndexAny(ref, "/#"); i >= 0 {
+		return ref[i+1:]
+	}
+	if i := strings.LastIndex(ref, ":"); i >= 0 {
+		return ref[i+1:]
+	}
+	return ref
+}
+
+func validateStructuredData(types []string, props map[string]interface{}) []string {
+	var errs []string
+	for _, itemType := range types {
+		for _, requirement := range structuredDataRequirements[itemType] {
+			if !hasAnyProperty(props, requirement) {
+				errs = append(errs, missingPropertyError(itemType, requirement))
+			}
+		}
+		if itemType == "BreadcrumbList" {
+			errs = append(errs, validateBreadcrumbs(props["itemListElement"])...)
+		}
+	}
+	return errs
+}
+
+func hasAnyProperty(props map[string]interface{}, names []string) bool {
+	for _, name := range names {
+		if hasStructuredValue(props[name]) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasStructuredValue(value interface{}) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case string:
+		return strings.TrimSpace(v) != ""
+	case []interface{}:
+		return len(v) > 0
+	case map[string]interface{}:
+		return len(v) > 0
+	default:
+		return true
+	}
+}
+
+func missingPropertyError(itemType string, requirement structuredDataRequirement) string {
+	if len(requirement) == 1 {
+		return fmt.Sprintf("%s is missing required property %q", itemType, requirement[0])
+	}
+	quoted := make([]string, len(requirement))
+	for i, name := range requirement {
+		quoted[i] = fmt.Sprintf("%q", name)
+	}
+	return fmt.Sprintf("%s is missing one of the properties %s", itemType, strings.Join(quoted, ", "))
+}
+
+// validateBreadcrumbs checks that every ListItem of a BreadcrumbList has a
+// position and a name, which may also be given on the linked item.
+func validateBreadcrumbs(value interface{}) []string {
+	var elements []interface{}
+	switch v := value.(type) {
+	case []interface{}:
+		elements = v
+	case map[string]interface{}:
+		elements = []interface{}{v}
+	default:
+		return nil
+	}
+
+	var errs []string
+	for i, element := range elements {
+		listItem, ok := element.(map[string]interface{})
+		if !ok {
+			errs = append(errs, fmt.Sprintf("itemListElement[%d] is not a ListItem", i))
+			continue
+		}
+		if !hasStructuredValue(listItem["position"]) {
+			errs = append(errs, fmt.Sprintf("itemListElement[%d] is missing required property \"position\"", i))
+		}
+		name := listItem["name"]
+		if linked, ok := listItem["item"].(map[string]interface{}); ok && !hasStructuredValue(name) {
+			name = linked["name"]
+		}
+		if !hasStructuredValue(name) {
+			errs = append(errs, fmt.Sprintf("itemListElement[%d] is missing required property \"name\"", i))
+		}
+	}
+	return errs
+}
+
+// collectItems finds top-level items in markup where scopeAttr starts an item
+// and propAttr names its properties, i.e. Microdata and RDFa. Elements that
+// carry both attributes are nested items and are kept inside their parent.
+func collectItems(sel *goquery.Selection, scopeAttr, propAttr string, build func(*goquery.Selection) map[string]interface{}) []map[string]interface{} {
+	var items []map[string]interface{}
+	sel.Children().Each(func(_ int, child *goquery.Selection) {
+		_, isScope := child.Attr(scopeAttr)
+		_, isProp := child.Attr(propAttr)
+		if isScope && !isProp {
+			items = append(items, build(child))
+			return
+		}
+		items = append(items, collectItems(child, scopeAttr, propAttr, build)...)
+	})
+	return items
+}
+
+func microdataItem(scope *goquery.Selection) map[string]interface{} {
+	props := make(map[string]interface{})
+	setStructuredType(props, scope.AttrOr("itemtype", ""))
+	collectProperties(scope, props, "itemscope", "itemprop", microdataItem)
+	return props
+}
+
+func rdfaItem(scope *goquery.Selection) map[string]interface{} {
+	props := make(map[string]interface{})
+	setStructuredType(props, scope.AttrOr("typeof", ""))
+	collectProperties(scope, props, "typeof", "property", rdfaItem)
+	return props
+}
+
+func setStructuredType(props map[string]interface{}, typeAttr string) {
+	types := strings.Fields(typeAttr)
+	switch len(types) {
+	case 0:
+	case 1:
+		props["@type"] = schemaTerm(types[0])
+	default:
+		values := make([]interface{}, len(types))
+		for i, t := range types {
+			values[i] = schemaTerm(t)
+		}
+		props["@type"] = values
+	}
+}
+
+// collectProperties adds the properties of the item rooted at sel to props.
+// Properties of nested items belong to those items and are not descended into.
+func collectProperties(sel *goquery.Selection, props map[string]interface{}, scopeAttr, propAttr string, build func(*goquery.Selection) map[string]interface{}) {
+	sel.Children().Each(func(_ int, child *goquery.Selection) {
+		_, isScope := child.Attr(scopeAttr)
+		if names, isProp := child.Attr(propAttr); isProp {
+			var value interface{}
+			if isScope {
+				value = build(child)
+			} else {
+				value = structuredPropertyValue(child)
+			}
+			for _, name := range strings.Fields(names) {
+				addStructuredProperty(props, schemaTerm(name), value)
+			}
+		}
+		if !isScope {
+			collectProperties(child, props, scopeAttr, propAttr, build)
+		}
+	})
+}
+
+// structuredPropertyValue reads a property value the way Microdata and RDFa
+// define it: from content, URL or machine-readable attributes before text.
+func structuredPropertyValue(sel *goquery.Selection) string {
+	if content, ok := sel.Attr("content"); ok {
+		return strings.TrimSpace(content)
+	}
+
+	var attrs []string
+	switch goquery.NodeName(sel) {
+	case "a", "area", "link":
+		attrs = []string{"href"}
+	case "img", "audio", "video", "source", "track", "iframe", "embed":
+		attrs = []string{"src"}
+	case "object":
+		attrs = []string{"data"}
+	case "time":
+		attrs = []string{"datetime"}
+	case "data", "meter":
+		attrs = []string{"value"}
+	}
+	attrs = append(attrs, "resource")
+	for _, attr := range attrs {
+		if value, ok := sel.Attr(attr); ok {
+			return strings.TrimSpace(value)
+		}
+	}
+
+	return strings.Join(strings.Fields(sel.Text()), " ")
+}
+
+// addStructuredProperty sets name on props, turning repeated properties into
+// a list.
+func addStructuredProperty(props map[string]interface{}, name string, value interface{}) {
+	existing, ok := props[name]
+	if !ok {
+		props[name] = value
+		return
+	}
+	if list, ok := existing.([]interface{}); ok {
+		props[name] = append(list, value)
+		return
+	}
+	props[name] = []interface{}{existing, value}
+}
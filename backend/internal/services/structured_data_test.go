@@ -0,0 +1,143 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/PuerkitoBio/goquery"
+	"sykell-crawler/internal/models"
+)
+
+func parseStructuredData(t *testing.T, html string) []models.StructuredDataItem {
+	t.Helper()
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	if err != nil {
+		t.Fatalf("Failed to parse HTML: %v", err)
+	}
+	return extractStructuredData(doc)
+}
+
+func TestExtractStructuredData_JSONLD(t *testing.T) {
+	items := parseStructuredData(t, `<html><head>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@type": "Product", "name": "Widget", "offers": {"@type": "Offer", "price": "9.99"}}
+</script>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@graph": [
+	{"@type": "Article", "headline": "News"},
+	{"@type": "BreadcrumbList", "itemListElement": [
+		{"@type": "ListItem", "position": 1, "name": "Home", "item": "https://example.com/"},
+		{"@type": "ListItem", "item": {"@id": "https://example.com/news", "name": "News"}}
+	]}
+]}
+</script>
+<script type="application/ld+json">{"@type": "Product", "name": "Broken",}</script>
+</head></html>`)
+
+	if len(items) != 4 {
+		t.Fatalf("Expected 4 items, got %d", len(items))
+	}
+
+	product := items[0]
+	if product.Format != models.StructuredDataJSONLD || product.Type != "Product" || !product.Valid {
+		t.Errorf("Expected a valid JSON-LD Product, got %+v", product)
+	}
+
+	article := items[1]
+	if article.Type != "Article" || article.Valid {
+		t.Errorf("Expected an invalid Article, got %+v", article)
+	}
+	if len(article.Errors) != 3 {
+		t.Errorf("Expected author, datePublished and image to be reported missing, got %v", article.Errors)
+	}
+
+	breadcrumbs := items[2]
+	if breadcrumbs.Type != "BreadcrumbList" || len(breadcrumbs.Errors) != 1 {
+		t.Fatalf("Expected one breadcrumb error, got %+v", breadcrumbs)
+	}
+	if !strings.Contains(breadcrumbs.Errors[0], "itemListElement[1]") || !strings.Contains(breadcrumbs.Errors[0], "position") {
+		t.Errorf("Expected the second ListItem to miss its position, got %q", breadcrumbs.Errors[0])
+	}
+
+	broken := items[3]
+	if broken.Valid || len(broken.Errors) != 1 || !strings.HasPrefix(broken.Errors[0], "invalid JSON") {
+		t.Errorf("Expected invalid JSON to be flagged, got %+v", broken)
+	}
+}
+
+func TestExtractStructuredData_Microdata(t *testing.T) {
+	items := parseStructuredData(t, `<html><body>
+<div itemscope itemtype="https://schema.org/Product">
+	<h1 itemprop="name">Widget</h1>
+	<img itemprop="image" src="/widget.png">
+	<div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+		<meta itemprop="price" content="9.99">
+		<span itemprop="name">Not the product name</span>
+	</div>
+</div>
+<div itemscope itemtype="https://schema.org/Product">
+	<span itemprop="name">No offers</span>
+</div>
+</body></html>`)
+
+	if len(items) != 2 {
+		t.Fatalf("Expected 2 items, got %d", len(items))
+	}
+
+	product := items[0]
+	if product.Format != models.StructuredDataMicrodata || product.Type != "Product" || !product.Valid {
+		t.Fatalf("Expected a valid Microdata Product, got %+v", product)
+	}
+	if product.Properties["name"] != "Widget" {
+		t.Errorf("Expected name 'Widget', got %v", product.Properties["name"])
+	}
+	if product.Properties["image"] != "/widget.png" {
+		t.Errorf("Expected image from src, got %v", product.Properties["image"])
+	}
+	offer, ok := product.Properties["offers"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("Expected nested offer, got %v", product.Properties["offers"])
+	}
+	if offer["@type"] != "Offer" || offer["price"] != "9.99" {
+		t.Errorf("Unexpected offer %v", offer)
+	}
+
+	if items[1].Valid || len(items[1].Errors) != 1 {
+		t.Errorf("Expected a Product without offers to be flagged, got %+v", items[1])
+	}
+}
+
+func TestExtractStructuredData_RDFa(t *testing.T) {
+	items := parseStructuredData(t, `<html><head><meta property="og:title" content="Ignored"></head><body>
+<ol vocab="https://schema.org/" typeof="BreadcrumbList">
+	<li property="itemListElement" typeof="ListItem">
+		<a property="item" href="/"><span property="name">Home</span></a>
+		<meta property="position" content="1">
+	</li>
+	<li property="itemListElement" typeof="ListItem">
+		<span property="name">Books</span>
+		<meta property="position" content="2">
+	</li>
+</ol>
+</body></html>`)
+
+	if len(items) != 1 {
+		t.Fatalf("Expected 1 item, got %d", len(items))
+	}
+
+	breadcrumbs := items[0]
+	if breadcrumbs.Format != models.StructuredDataRDFa || breadcrumbs.Type != "BreadcrumbList" || !breadcrumbs.Valid {
+		t.Fatalf("Expected a valid RDFa BreadcrumbList, got %+v", breadcrumbs)
+	}
+	elements, ok := breadcrumbs.Properties["itemListElement"].([]interface{})
+	if !ok || len(elements) != 2 {
+		t.Fatalf("Expected 2 list items, got %v", breadcrumbs.Properties["itemListElement"])
+	}
+}
+
+func TestExtractStructuredData_LongType(t *testing.T) {
+	items := parseStructuredData(t, `<script type="application/ld+json">{"@type": "`+strings.Repeat("Thing", 40)+`"}</script>`)
+	if len(items) != 1 || len(items[0].Type) != maxStructuredDataTypeLength {
+		t.Errorf("Expected the type to fit its column, got %+v", items)
+	}
+}
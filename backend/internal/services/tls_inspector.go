@@ -0,0 +1,168 @@
+package services
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"time"
+
+	"sykell-crawler/internal/models"
+)
+
+const (
+	// defaultTLSExpiryWarning is used when the configuration does not say how
+	// early an expiring certificate should be flagged.
+	defaultTLSExpiryWarning = 30 * 24 * time.Hour
+	// tlsProbeTimeout bounds the extra handshake made to read a certificate
+	// the crawl request rejected.
+	tlsProbeTimeout = 10 * time.Second
+)
+
+// tlsCertSeverity orders certificate statuses so the worst issue wins.
+var tlsCertSeverity = map[string]int{
+	models.TLSCertValid:            0,
+	models.TLSCertExpiringSoon:     1,
+	models.TLSCertNotYetValid:      2,
+	models.TLSCertUntrusted:        3,
+	models.TLSCertHostnameMismatch: 4,
+	models.TLSCertExpired:          5,
+}
+
+// inspectTLS describes the TLS connection of a page response, or returns nil
+// when the page was not served over TLS.
+func (s *crawlerService) inspectTLS(target string, state *tls.ConnectionState) *models.TLSInfo {
+	if state == nil || len(state.PeerCertificates) == 0 {
+		return nil
+	}
+	return s.describeTLS(target, state)
+}
+
+// probeTLS makes a separate handshake without verification to read the
+// certificate that made the crawl request fail.
+func (s *crawlerService) probeTLS(target string, certErr *tls.CertificateVerificationError) *models.TLSInfo {
+	parsed, err := url.Parse(target)
+	if err != nil {
+		return nil
+	}
+	address := parsed.Host
+	if parsed.Port() == "" {
+		address = net.JoinHostPort(parsed.Hostname(), "443")
+	}
+
+	dialer := &net.Dialer{Timeout: tlsProbeTimeout}
+	conn, err := tls.DialWithDialer(dialer, "tcp", address, &tls.Config{
+		ServerName:         parsed.Hostname(),
+		InsecureSkipVerify: true,
+	})
+	if err != nil {
+		return nil
+	}
+	defer conn.Close()
+
+	state := conn.ConnectionState()
+	info := s.describeTLS(target, &state)
+
+	// Expiry and hostname problems are found by describeTLS; anything else the
+	// verifier rejected, such as an unknown authority, means the chain is untrusted
+	var unknownAuthority x509.UnknownAuthorityError
+	if errors.As(certErr, &unknownAuthority) || info.Status == models.TLSCertValid || info.Status == models.TLSCertExpiringSoon {
+		addTLSIssue(info, models.TLSCertUntrusted, certErr.Err.Error())
+	}
+	return info
+}
+
+func (s *crawlerService) describeTLS(target string, state *tls.ConnectionState) *models.TLSInfo {
+	info := &models.TLSInfo{
+		Version:     tls.VersionName(state.Version),
+		CipherSuite: tls.CipherSuiteName(state.CipherSuite),
+		ServerName:  state.ServerName,
+		Status:      models.TLSCertValid,
+	}
+	for _, cert := range state.PeerCertificates {
+		info.Certificates = append(info.Certificates, describeCertificate(cert))
+	}
+
+	leaf := state.PeerCertificates[0]
+	now := time.Now()
+	switch {
+	case now.After(leaf.NotAfter):
+		addTLSIssue(info, models.TLSCertExpired, fmt.Sprintf("certificate expired on %s", leaf.NotAfter.Format(time.RFC3339)))
+	case now.Before(leaf.NotBefore):
+		addTLSIssue(info, models.TLSCertNotYetValid, fmt.Sprintf("certificate is not valid before %s", leaf.NotBefore.Format(time.RFC3339)))
+	case leaf.NotAfter.Sub(now) < s.tlsExpiryWarning():
+		addTLSIssue(info, models.TLSCertExpiringSoon, fmt.Sprintf("certificate expires on %s", leaf.NotAfter.Format(time.RFC3339)))
+	}
+
+	if parsed, err := url.Parse(target); err == nil {
+		if err := leaf.VerifyHostname(parsed.Hostname()); err != nil {
+			addTLSIssue(info, models.TLSCertHostnameMismatch, err.Error())
+		}
+	}
+
+	return info
+}
+
+func (s *crawlerService) tlsExpiryWarning() time.Duration {
+	if s.config == nil || s.config.TLSExpiryWarning <= 0 {
+		return defaultTLSExpiryWarning
+	}
+	return s.config.TLSExpiryWarning
+}
+
+// addTLSIssue records an issue and raises the status if it is more severe.
+func addTLSIssue(info *models.TLSInfo, status, issue string) {
+	info.Issues = append(info.Issues, issue)
+	if tlsCertSeverity[status] > tlsCertSeverity[info.Status] {
+		info.Status = status
+	}
+}
+
+func describeCertificate(cert *x509.Certificate) models.CertificateInfo {
+	info := models.CertificateInfo{
+		Subject:            cert.Subject.String(),
+		Issuer:             cert.Issuer.String(),
+		DNSNames:           cert.DNSNames,
+		SerialNumber:       cert.SerialNumber.String(),
+		NotBefore:          cert.NotBefore,
+		NotAfter:           cert.NotAfter,
+		KeyType:            certificateKeyType(cert),
+		SignatureAlgorithm: cert.SignatureAlgorithm.String(),
+		IsCA:               cert.IsCA,
+	}
+	for _, ip := range cert.IPAddresses {
+		info.IPAddresses = append(info.IPAddresses, ip.String())
+	}
+	return info
+}
+
+func certificateKeyType(cert *x509.Certificate) string {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return fmt.Sprintf("RSA %d", key.N.BitLen())
+	case *ecdsa.PublicKey:
+		return "ECDSA " + key.Curve.Params().Name
+	case ed25519.PublicKey:
+		return "Ed25519"
+	}
+	return cert.PublicKeyAlgorithm.String()
+}
+
+// setTLSInfo stores the TLS details on a result along with the columns the
+// URL list sorts by.
+func setTLSInfo(result *models.CrawlResult, info *models.TLSInfo) {
+	if info == nil {
+		return
+	}
+	result.TLS = info
+	result.TLSCertStatus = info.Status
+	if len(info.Certificates) > 0 {
+		expiresAt := info.Certificates[0].NotAfter
+		result.TLSCertExpiresAt = &expiresAt
+	}
+}
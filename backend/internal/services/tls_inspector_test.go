@@ -0,0 +1,104 @@
+package services
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"sykell-crawler/internal/models"
+)
+
+func newTLSTestService(server *httptest.Server, cfg func(*crawlerService)) *crawlerService {
+	service := NewCrawlerService(nil, nil, nil, nil, createTestConfig()).(*crawlerService)
+	service.client.Transport = server.Client().Transport
+	if cfg != nil {
+		cfg(service)
+	}
+	return service
+}
+
+func TestFetchPage_InspectsTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<html></html>`))
+	}))
+	defer server.Close()
+
+	service := newTLSTestService(server, nil)
+	fetch, err := service.fetchPage(server.URL, robotsPolicy{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer fetch.Response.Body.Close()
+
+	info := fetch.TLS
+	if info == nil {
+		t.Fatal("Expected TLS details for an https page")
+	}
+	if info.Status != models.TLSCertValid {
+		t.Errorf("Expected a valid certificate, got '%s' (%v)", info.Status, info.Issues)
+	}
+	if !strings.HasPrefix(info.Version, "TLS 1.") || info.CipherSuite == "" {
+		t.Errorf("Expected the negotiated version and cipher, got '%s' and '%s'", info.Version, info.CipherSuite)
+	}
+	if len(info.Certificates) == 0 || len(info.Certificates[0].DNSNames) == 0 || info.Certificates[0].KeyType == "" {
+		t.Errorf("Expected the leaf certificate to be described, got %+v", info.Certificates)
+	}
+}
+
+func TestFetchPage_FlagsExpiringCertificate(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	service := newTLSTestService(server, func(s *crawlerService) {
+		// The test certificate is valid for decades, so warn for longer than that
+		s.config.TLSExpiryWarning = 200 * 365 * 24 * time.Hour
+	})
+	fetch, err := service.fetchPage(server.URL, robotsPolicy{})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	defer fetch.Response.Body.Close()
+
+	result := &models.CrawlResult{}
+	setTLSInfo(result, fetch.TLS)
+	if result.TLSCertStatus != models.TLSCertExpiringSoon {
+		t.Errorf("Expected status '%s', got '%s'", models.TLSCertExpiringSoon, result.TLSCertStatus)
+	}
+	if result.TLSCertExpiresAt == nil {
+		t.Error("Expected the expiry to be stored on the result")
+	}
+}
+
+func TestFetchPage_ReportsRejectedCertificates(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	t.Run("Untrusted", func(t *testing.T) {
+		service := NewCrawlerService(nil, nil, nil, nil, createTestConfig()).(*crawlerService)
+		_, err := service.fetchPage(server.URL, robotsPolicy{})
+		if err == nil {
+			t.Fatal("Expected the self-signed certificate to be rejected")
+		}
+
+		result := failedResult(err)
+		if result.TLSCertStatus != models.TLSCertUntrusted {
+			t.Errorf("Expected status '%s', got '%s'", models.TLSCertUntrusted, result.TLSCertStatus)
+		}
+	})
+
+	t.Run("Hostname mismatch", func(t *testing.T) {
+		// The test certificate covers 127.0.0.1 and example.com but not localhost
+		service := newTLSTestService(server, nil)
+		_, err := service.fetchPage(strings.Replace(server.URL, "127.0.0.1", "localhost", 1), robotsPolicy{})
+		if err == nil {
+			t.Fatal("Expected the certificate to be rejected for localhost")
+		}
+
+		result := failedResult(err)
+		if result.TLSCertStatus != models.TLSCertHostnameMismatch {
+			t.Errorf("Expected status '%s', got '%s'", models.TLSCertHostnameMismatch, result.TLSCertStatus)
+		}
+	})
+}
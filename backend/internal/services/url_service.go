@@ -2,6 +2,7 @@ package services
 
 import (
 	"errors"
+	"fmt"
 	"net/url"
 	"strings"
 	"sykell-crawler/internal/models"
@@ -11,40 +12,92 @@ import (
 )
 
 type AddURLResult struct {
-	URL     *models.URL `json:"url"`
-	Message string      `json:"message,omitempty"`
-	IsNew   bool        `json:"is_new"`
+	URL        *models.URL `json:"url"`
+	Message    string      `json:"message,omitempty"`
+	IsNew      bool        `json:"is_new"`
+	IsRestored bool        `json:"is_restored,omitempty"`
+}
+
+// AddURLOptions controls how a newly added URL is crawled.
+type AddURLOptions struct {
+	Mode           models.CrawlMode
+	MaxDepth       int
+	MaxPages       int
+	IgnoreRobots   bool
+	ScopeMode      models.ScopeMode
+	ScopeHosts     []string
+	Group          string
+	Analyzers      map[string]bool
+	CrawlProfileID *uint
+}
+
+// URLSettings are the settings of a URL that can be changed after it was
+// added. Nil fields are left unchanged. They apply from the next crawl. A
+// CrawlProfileID of 0 unassigns the URL's profile.
+type URLSettings struct {
+	ScopeMode      *models.ScopeMode
+	ScopeHosts     *[]string
+	Group          *string
+	Analyzers      *map[string]bool
+	CrawlProfileID *uint
 }
 
 type URLService interface {
-	AddURL(urlStr string) (*AddURLResult, error)
+	AddURL(urlStr string, opts AddURLOptions) (*AddURLResult, error)
 	GetURL(id uint) (*models.URL, error)
 	GetAllURLs(page, pageSize int, search, sortBy, sortOrder string) ([]*models.URL, int64, error)
 	StartCrawling(ids []uint) error
 	StopCrawling(ids []uint) error
 	DeleteURLs(ids []uint) error
 	RecrawlURLs(ids []uint) error
+	GetLinks(id uint, filter repositories.LinkFilter, page, pageSize int) ([]*models.Link, int64, error)
+	UpdateSettings(id uint, settings URLSettings) (*models.URL, error)
+	GetDuplicates(opts DuplicateOptions, page, pageSize int) ([]DuplicateCluster, int64, error)
 }
 
+var (
+	// ErrURLNotFound is returned when a URL does not exist.
+	ErrURLNotFound = errors.New("URL not found")
+	// ErrInvalidSettings is returned when URL settings fail validation.
+	ErrInvalidSettings = errors.New("invalid settings")
+)
+
 type urlService struct {
-	urlRepo repositories.URLRepository
-	queue   QueueService
+	urlRepo     repositories.URLRepository
+	profileRepo repositories.CrawlProfileRepository
+	queue       QueueService
 }
 
-func NewURLService(urlRepo repositories.URLRepository, queue QueueService) URLService {
+func NewURLService(urlRepo repositories.URLRepository, profileRepo repositories.CrawlProfileRepository, queue QueueService) URLService {
 	return &urlService{
-		urlRepo: urlRepo,
-		queue:   queue,
+		urlRepo:     urlRepo,
+		profileRepo: profileRepo,
+		queue:       queue,
 	}
 }
 
-func (s *urlService) AddURL(urlStr string) (*AddURLResult, error) {
+func (s *urlService) AddURL(urlStr string, opts AddURLOptions) (*AddURLResult, error) {
 	if !s.isValidURL(urlStr) {
 		return nil, errors.New("invalid URL format")
 	}
 
 	urlStr = s.normalizeURL(urlStr)
 
+	if !validScopeMode(opts.ScopeMode) {
+		return nil, fmt.Errorf("invalid scope mode %q", opts.ScopeMode)
+	}
+	scopeHosts, err := normalizeScopeHosts(opts.ScopeHosts)
+	if err != nil {
+		return nil, err
+	}
+	opts.ScopeHosts = scopeHosts
+	if err := validateAnalyzers(opts.Analyzers); err != nil {
+		return nil, err
+	}
+	if err := s.validateProfile(opts.CrawlProfileID); err != nil {
+		return nil, err
+	}
+
 	// First, check for existing active URL
 	existing, err := s.urlRepo.GetByURL(urlStr)
 	if err == nil {
@@ -65,25 +118,28 @@ func (s *urlService) AddURL(urlStr string) (*AddURLResult, error) {
 			return nil, err
 		}
 
-		// Update status and enqueue
-		if err := s.urlRepo.UpdateStatus(existingDeleted.ID, models.StatusQueued); err != nil {
+		restoredURL, err := s.urlRepo.GetByID(existingDeleted.ID)
+		if err != nil {
 			return nil, err
 		}
 
-		if err := s.queue.EnqueueCrawlJob(existingDeleted.ID); err != nil {
-			s.urlRepo.UpdateStatus(existingDeleted.ID, models.StatusError)
+		// Update status and crawl settings, then enqueue
+		restoredURL.Status = models.StatusQueued
+		opts.apply(restoredURL)
+		if err := s.urlRepo.Update(restoredURL); err != nil {
 			return nil, err
 		}
 
-		// Return the restored URL
-		restoredURL, err := s.urlRepo.GetByID(existingDeleted.ID)
-		if err != nil {
+		if err := s.enqueueCrawl(restoredURL); err != nil {
+			s.urlRepo.UpdateStatus(restoredURL.ID, models.StatusError)
 			return nil, err
 		}
+
 		return &AddURLResult{
-			URL:     restoredURL,
-			Message: "URL restored and queued for crawling",
-			IsNew:   false,
+			URL:        restoredURL,
+			Message:    "URL restored and queued for crawling",
+			IsNew:      false,
+			IsRestored: true,
 		}, nil
 	}
 
@@ -92,12 +148,13 @@ func (s *urlService) AddURL(urlStr string) (*AddURLResult, error) {
 		URL:    urlStr,
 		Status: models.StatusQueued,
 	}
+	opts.apply(newURL)
 
 	if err := s.urlRepo.Create(newURL); err != nil {
 		return nil, err
 	}
 
-	if err := s.queue.EnqueueCrawlJob(newURL.ID); err != nil {
+	if err := s.enqueueCrawl(newURL); err != nil {
 		s.urlRepo.UpdateStatus(newURL.ID, models.StatusError)
 		return nil, err
 	}
@@ -118,6 +175,100 @@ func (s *urlService) GetAllURLs(page, pageSize int, search, sortBy, sortOrder st
 	return s.urlRepo.GetAll(offset, pageSize, search, sortBy, sortOrder)
 }
 
+// GetLinks lists a page of the links found on a URL.
+func (s *urlService) GetLinks(id uint, filter repositories.LinkFilter, page, pageSize int) ([]*models.Link, int64, error) {
+	urls, err := s.urlRepo.GetByIDs([]uint{id})
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(urls) == 0 {
+		return nil, 0, ErrURLNotFound
+	}
+	offset := (page - 1) * pageSize
+	return s.urlRepo.GetLinks(id, filter, offset, pageSize)
+}
+
+// GetDuplicates lists a page of the clusters of URLs whose latest crawls
+// share a title, meta description or content.
+func (s *urlService) GetDuplicates(opts DuplicateOptions, page, pageSize int) ([]DuplicateCluster, int64, error) {
+	fingerprints, err := s.urlRepo.GetFingerprints()
+	if err != nil {
+		return nil, 0, err
+	}
+	clusters := findDuplicates(fingerprints, opts)
+	total := int64(len(clusters))
+
+	offset := (page - 1) * pageSize
+	if offset >= len(clusters) {
+		return []DuplicateCluster{}, total, nil
+	}
+	end := offset + pageSize
+	if end > len(clusters) {
+		end = len(clusters)
+	}
+	return clusters[offset:end], total, nil
+}
+
+// UpdateSettings changes the settings of a URL.
+func (s *urlService) UpdateSettings(id uint, settings URLSettings) (*models.URL, error) {
+	urls, err := s.urlRepo.GetByIDs([]uint{id})
+	if err != nil {
+		return nil, err
+	}
+	if len(urls) == 0 {
+		return nil, ErrURLNotFound
+	}
+	url := urls[0]
+
+	// Only the changed columns are written, since a crawl may be updating
+	// the URL's status at the same time
+	var columns []string
+	if settings.ScopeMode != nil {
+		if *settings.ScopeMode == "" || !validScopeMode(*settings.ScopeMode) {
+			return nil, fmt.Errorf("%w: scope mode %q", ErrInvalidSettings, *settings.ScopeMode)
+		}
+		url.ScopeMode = *settings.ScopeMode
+		columns = append(columns, "scope_mode")
+	}
+	if settings.ScopeHosts != nil {
+		hosts, err := normalizeScopeHosts(*settings.ScopeHosts)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSettings, err)
+		}
+		url.ScopeHosts = hosts
+		columns = append(columns, "scope_hosts")
+	}
+	if settings.Group != nil {
+		url.Group = strings.TrimSpace(*settings.Group)
+		columns = append(columns, "group_name")
+	}
+	if settings.Analyzers != nil {
+		if err := validateAnalyzers(*settings.Analyzers); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSettings, err)
+		}
+		url.Analyzers = *settings.Analyzers
+		columns = append(columns, "analyzers")
+	}
+	if settings.CrawlProfileID != nil {
+		url.CrawlProfileID = nil
+		if *settings.CrawlProfileID != 0 {
+			if err := s.validateProfile(settings.CrawlProfileID); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidSettings, err)
+			}
+			url.CrawlProfileID = settings.CrawlProfileID
+		}
+		columns = append(columns, "crawl_profile_id")
+	}
+
+	if len(columns) == 0 {
+		return url, nil
+	}
+	if err := s.urlRepo.UpdateColumns(url, columns...); err != nil {
+		return nil, err
+	}
+	return url, nil
+}
+
 func (s *urlService) StartCrawling(ids []uint) error {
 	urls, err := s.urlRepo.GetByIDs(ids)
 	if err != nil {
@@ -133,7 +284,7 @@ func (s *urlService) StartCrawling(ids []uint) error {
 			continue
 		}
 
-		if err := s.queue.EnqueueCrawlJob(url.ID); err != nil {
+		if err := s.enqueueCrawl(url); err != nil {
 			s.urlRepo.UpdateStatus(url.ID, models.StatusError)
 		}
 	}
@@ -183,6 +334,71 @@ func (s *urlService) RecrawlURLs(ids []uint) error {
 	return s.StartCrawling(ids)
 }
 
+// enqueueCrawl queues the kind of crawl job the URL is configured for.
+func (s *urlService) enqueueCrawl(url *models.URL) error {
+	if url.CrawlMode == models.CrawlModeSite {
+		return s.queue.EnqueueSiteCrawlJob(url.ID, url.MaxDepth, url.MaxPages)
+	}
+	return s.queue.EnqueueCrawlJob(url.ID)
+}
+
+func (opts AddURLOptions) apply(url *models.URL) {
+	url.CrawlMode = opts.Mode
+	if url.CrawlMode == "" {
+		url.CrawlMode = models.CrawlModePage
+	}
+	url.IgnoreRobots = opts.IgnoreRobots
+	url.ScopeMode = opts.ScopeMode
+	if url.ScopeMode == "" {
+		url.ScopeMode = models.ScopeHost
+	}
+	url.ScopeHosts = opts.ScopeHosts
+	url.Group = strings.TrimSpace(opts.Group)
+	url.Analyzers = opts.Analyzers
+	url.CrawlProfileID = opts.CrawlProfileID
+	// A loaded profile would overwrite CrawlProfileID when the URL is saved
+	url.CrawlProfile = nil
+	url.MaxDepth = 0
+	url.MaxPages = 0
+	if url.CrawlMode == models.CrawlModeSite {
+		url.MaxDepth = opts.MaxDepth
+		url.MaxPages = opts.MaxPages
+	}
+}
+
+// validateProfile checks that the crawl profile a URL is assigned to exists.
+func (s *urlService) validateProfile(id *uint) error {
+	if id == nil {
+		return nil
+	}
+	if _, err := s.profileRepo.GetByID(*id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("crawl profile %d does not exist", *id)
+		}
+		return err
+	}
+	return nil
+}
+
+func validScopeMode(mode models.ScopeMode) bool {
+	switch mode {
+	case "", models.ScopeHost, models.ScopeSubdomains, models.ScopeDomain:
+		return true
+	}
+	return false
+}
+
+// validateAnalyzers checks that per-URL analyzer overrides name registered
+// analyzers.
+func validateAnalyzers(overrides map[string]bool) error {
+	for name := range overrides {
+		if !LookupAnalyzer(name) {
+			return fmt.Errorf("unknown analyzer %q", name)
+		}
+	}
+	return nil
+}
+
 func (s *urlService) isValidURL(urlStr string) bool {
 	u, err := url.Parse(urlStr)
 	if err != nil {
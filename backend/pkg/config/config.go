@@ -4,26 +4,49 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
 type Config struct {
-	DatabaseURL       string
-	RedisURL          string
-	JWTSecret         string
-	AllowedOrigins    []string
-	HTTPTimeout       time.Duration
-	LinkCheckTimeout  time.Duration
+	DatabaseURL          string
+	RedisURL             string
+	JWTSecret            string
+	AllowedOrigins       []string
+	HTTPTimeout          time.Duration
+	MaxRedirects         int
+	TLSExpiryWarning     time.Duration
+	LinkCheckTimeout     time.Duration
+	LinkCheckConcurrency int
+	LinkCheckPerHost     int
+	SiteCrawlMaxDepth    int
+	SiteCrawlMaxPages    int
+	RobotsUserAgent      string
+	RobotsCacheTTL       time.Duration
+	SitemapMaxURLs       int
+	DisabledAnalyzers    []string
+	CredentialsKey       string
 }
 
 func Load() *Config {
 	cfg := &Config{
-		DatabaseURL:      getEnv("DATABASE_URL", "root:password@tcp(localhost:3306)/sykell_crawler?charset=utf8mb4&parseTime=True&loc=Local"),
-		RedisURL:         getEnv("REDIS_URL", "localhost:6379"),
-		JWTSecret:        getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
-		AllowedOrigins:   []string{getEnv("FRONTEND_URL", "http://localhost:5173")},
-		HTTPTimeout:      getDurationEnv("HTTP_TIMEOUT", 30*time.Second),
-		LinkCheckTimeout: getDurationEnv("LINK_CHECK_TIMEOUT", 10*time.Second),
+		DatabaseURL:          getEnv("DATABASE_URL", "root:password@tcp(localhost:3306)/sykell_crawler?charset=utf8mb4&parseTime=True&loc=Local"),
+		RedisURL:             getEnv("REDIS_URL", "localhost:6379"),
+		JWTSecret:            getEnv("JWT_SECRET", "your-secret-key-change-in-production"),
+		AllowedOrigins:       []string{getEnv("FRONTEND_URL", "http://localhost:5173")},
+		HTTPTimeout:          getDurationEnv("HTTP_TIMEOUT", 30*time.Second),
+		MaxRedirects:         getIntEnv("MAX_REDIRECTS", 10),
+		TLSExpiryWarning:     getDurationEnv("TLS_EXPIRY_WARNING", 30*24*time.Hour),
+		LinkCheckTimeout:     getDurationEnv("LINK_CHECK_TIMEOUT", 10*time.Second),
+		LinkCheckConcurrency: getIntEnv("LINK_CHECK_CONCURRENCY", 10),
+		LinkCheckPerHost:     getIntEnv("LINK_CHECK_PER_HOST", 2),
+		SiteCrawlMaxDepth:    getIntEnv("SITE_CRAWL_MAX_DEPTH", 3),
+		SiteCrawlMaxPages:    getIntEnv("SITE_CRAWL_MAX_PAGES", 50),
+		RobotsUserAgent:      getEnv("ROBOTS_USER_AGENT", "sykell-crawler"),
+		RobotsCacheTTL:       getDurationEnv("ROBOTS_CACHE_TTL", 24*time.Hour),
+		SitemapMaxURLs:       getIntEnv("SITEMAP_MAX_URLS", 1000),
+		DisabledAnalyzers:    getListEnv("DISABLED_ANALYZERS"),
+		CredentialsKey:       getEnv("CREDENTIALS_KEY", ""),
 	}
 
 	if err := cfg.validate(); err != nil {
@@ -42,6 +65,10 @@ func (c *Config) validate() error {
 		return fmt.Errorf("JWT_SECRET must be at least 32 characters long")
 	}
 
+	if c.CredentialsKey != "" && len(c.CredentialsKey) < 32 {
+		return fmt.Errorf("CREDENTIALS_KEY must be at least 32 characters long")
+	}
+
 	if c.DatabaseURL == "" {
 		return fmt.Errorf("DATABASE_URL is required")
 	}
@@ -60,6 +87,26 @@ func getEnv(key, defaultValue string) string {
 	return defaultValue
 }
 
+func getIntEnv(key string, defaultValue int) int {
+	if value := os.Getenv(key); value != "" {
+		if n, err := strconv.Atoi(value); err == nil {
+			return n
+		}
+	}
+	return defaultValue
+}
+
+// getListEnv splits a comma-separated variable, dropping empty entries.
+func getListEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
 func getDurationEnv(key string, defaultValue time.Duration) time.Duration {
 	if value := os.Getenv(key); value != "" {
 		if seconds, err := strconv.Atoi(value); err == nil {
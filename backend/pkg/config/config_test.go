@@ -57,6 +57,26 @@ func TestConfig_validate_ShortJWTSecret(t *testing.T) {
 	}
 }
 
+func TestConfig_validate_ShortCredentialsKey(t *testing.T) {
+	cfg := &Config{
+		DatabaseURL:    "root:password@tcp(localhost:3306)/test_db",
+		RedisURL:       "localhost:6379",
+		JWTSecret:      "this-is-a-very-secure-jwt-secret-key-with-more-than-32-characters",
+		AllowedOrigins: []string{"http://localhost:3000"},
+		CredentialsKey: "short-key",
+	}
+
+	err := cfg.validate()
+	if err == nil {
+		t.Error("Expected validation error for short credentials key")
+	}
+
+	expected := "CREDENTIALS_KEY must be at least 32 characters long"
+	if err.Error() != expected {
+		t.Errorf("Expected error '%s', got '%s'", expected, err.Error())
+	}
+}
+
 func TestConfig_validate_EmptyDatabaseURL(t *testing.T) {
 	cfg := &Config{
 		DatabaseURL:    "",
@@ -194,4 +214,38 @@ func TestLoad_WithDefaults(t *testing.T) {
 	if len(cfg.AllowedOrigins) != 1 || cfg.AllowedOrigins[0] != "http://localhost:5173" {
 		t.Errorf("Expected default AllowedOrigins, got %v", cfg.AllowedOrigins)
 	}
-}
\ No newline at end of file
+}
+
+func TestGetIntEnv(t *testing.T) {
+	key := "TEST_INT_ENV_VAR"
+
+	os.Setenv(key, "25")
+	if result := getIntEnv(key, 10); result != 25 {
+		t.Errorf("Expected 25, got %d", result)
+	}
+
+	os.Setenv(key, "not-a-number")
+	if result := getIntEnv(key, 10); result != 10 {
+		t.Errorf("Expected default 10 for invalid value, got %d", result)
+	}
+
+	os.Unsetenv(key)
+	if result := getIntEnv(key, 10); result != 10 {
+		t.Errorf("Expected default 10 for unset value, got %d", result)
+	}
+}
+
+func TestGetListEnv(t *testing.T) {
+	key := "TEST_LIST_ENV_VAR"
+
+	os.Setenv(key, " meta-refresh, ,custom-check ")
+	result := getListEnv(key)
+	if len(result) != 2 || result[0] != "meta-refresh" || result[1] != "custom-check" {
+		t.Errorf("Expected [meta-refresh custom-check], got %v", result)
+	}
+
+	os.Unsetenv(key)
+	if result := getListEnv(key); len(result) != 0 {
+		t.Errorf("Expected no values for unset variable, got %v", result)
+	}
+}
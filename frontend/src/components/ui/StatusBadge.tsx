@@ -1,7 +1,7 @@
 import React from 'react';
 import { Badge } from './Badge';
 
-export type StatusType = 'queued' | 'running' | 'done' | 'error' | 'stopped';
+export type StatusType = 'queued' | 'running' | 'done' | 'error' | 'stopped' | 'unsupported';
 
 export interface StatusBadgeProps {
   status: StatusType;
@@ -29,6 +29,10 @@ const statusConfig = {
     variant: 'secondary' as const,
     gradient: 'bg-gradient-to-r from-gray-400 to-slate-500',
   },
+  unsupported: {
+    variant: 'secondary' as const,
+    gradient: 'bg-gradient-to-r from-gray-400 to-slate-500',
+  },
 };
 
 export const StatusBadge: React.FC<StatusBadgeProps> = ({ status, className = '' }) => {
@@ -41,6 +45,7 @@ export const StatusBadge: React.FC<StatusBadgeProps> = ({ status, className = ''
       case 'done': return 'Website crawl completed successfully';
       case 'error': return 'An error occurred during website crawling';
       case 'stopped': return 'Website crawling has been stopped';
+      case 'unsupported': return 'The URL does not serve an HTML page';
       default: return `Status: ${status}`;
     }
   };
@@ -66,6 +71,7 @@ export const StatusIndicatorBar: React.FC<StatusBadgeProps> = ({ status }) => {
       case 'done': return 'Processing completed';
       case 'error': return 'Processing failed';
       case 'stopped': return 'Processing stopped';
+      case 'unsupported': return 'Unsupported content';
       default: return `Status: ${status}`;
     }
   };
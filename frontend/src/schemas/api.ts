@@ -1,7 +1,7 @@
 import { z } from 'zod';
 
 // Base schemas for common data types
-export const CrawlStatusSchema = z.enum(['queued', 'running', 'done', 'error', 'stopped']);
+export const CrawlStatusSchema = z.enum(['queued', 'running', 'done', 'error', 'stopped', 'unsupported']);
 
 export const UserSchema = z.object({
   id: z.number(),
@@ -1,4 +1,4 @@
-export type CrawlStatus = 'queued' | 'running' | 'done' | 'error' | 'stopped';
+export type CrawlStatus = 'queued' | 'running' | 'done' | 'error' | 'stopped' | 'unsupported';
 
 export interface URL {
   id: number;